@@ -0,0 +1,211 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/ferretcode/pricetag/errors"
+	"github.com/ferretcode/pricetag/forwarder"
+	"github.com/ferretcode/pricetag/logstore"
+	"github.com/ferretcode/pricetag/matcher"
+	"github.com/ferretcode/pricetag/middleware"
+	"github.com/ferretcode/pricetag/routes/forwarding"
+	"github.com/ferretcode/pricetag/routes/logs"
+	"github.com/ferretcode/pricetag/routes/services"
+	"github.com/ferretcode/pricetag/routes/tags"
+	"github.com/ferretcode/pricetag/routes/user"
+	"github.com/ferretcode/pricetag/routes/users"
+	"github.com/ferretcode/pricetag/sources/railway"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+// registerAPIHandlers mounts the json api. Every area is gated by the same
+// permission as its dashboard page
+func registerAPIHandlers(r chi.Router, db *sqlx.DB, tagMatcher *matcher.Matcher, logForwarder *forwarder.Forwarder, logStore logstore.Store, gql *railway.GraphQLConfig, railwayConfig *railway.Config) {
+	r.Route("/api/v1", func(r chi.Router) {
+		r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+			errors.HandleAPIError(w, r.URL.Path, http.StatusNotFound, "not found")
+		})
+
+		r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+			errors.HandleAPIError(w, r.URL.Path, http.StatusMethodNotAllowed, "method not allowed")
+		})
+
+		r.Use(middleware.CheckAPIUser(db, sessionManager))
+
+		r.Get("/me", func(w http.ResponseWriter, r *http.Request) {
+			status, err := user.APIMe(w, r)
+			if err != nil {
+				errors.HandleAPIError(w, "GET /api/v1/me", status, err.Error())
+			}
+		})
+
+		r.Route("/tags", func(r chi.Router) {
+			r.Use(middleware.RequireAPIPermission(types.Permission.CanManageTags))
+
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := tags.APIList(w, r, tagMatcher)
+				if err != nil {
+					errors.HandleAPIError(w, "GET /api/v1/tags", status, err.Error())
+				}
+			})
+
+			r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := tags.APICreate(w, r, db, tagMatcher)
+				if err != nil {
+					errors.HandleAPIError(w, "POST /api/v1/tags", status, err.Error())
+				}
+			})
+
+			r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+				status, err := tags.APIGet(w, r, db)
+				if err != nil {
+					errors.HandleAPIError(w, "GET /api/v1/tags/{id}", status, err.Error())
+				}
+			})
+
+			r.Put("/{id}", func(w http.ResponseWriter, r *http.Request) {
+				status, err := tags.APIUpdate(w, r, db, tagMatcher)
+				if err != nil {
+					errors.HandleAPIError(w, "PUT /api/v1/tags/{id}", status, err.Error())
+				}
+			})
+
+			r.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
+				status, err := tags.APIDelete(w, r, db, tagMatcher)
+				if err != nil {
+					errors.HandleAPIError(w, "DELETE /api/v1/tags/{id}", status, err.Error())
+				}
+			})
+		})
+
+		r.Route("/services", func(r chi.Router) {
+			r.Use(middleware.RequireAPIPermission(types.Permission.CanManageServices))
+
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := services.APIList(w, r, db, gql, railwayConfig)
+				if err != nil {
+					errors.HandleAPIError(w, "GET /api/v1/services", status, err.Error())
+				}
+			})
+
+			r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := services.APITrack(w, r, db, gql, railwayConfig)
+				if err != nil {
+					errors.HandleAPIError(w, "POST /api/v1/services", status, err.Error())
+				}
+			})
+
+			r.Delete("/{serviceID}", func(w http.ResponseWriter, r *http.Request) {
+				status, err := services.APIUntrack(w, r, db, railwayConfig)
+				if err != nil {
+					errors.HandleAPIError(w, "DELETE /api/v1/services/{serviceID}", status, err.Error())
+				}
+			})
+
+			r.Get("/environments", func(w http.ResponseWriter, r *http.Request) {
+				status, err := services.APIListEnvironments(w, r, db)
+				if err != nil {
+					errors.HandleAPIError(w, "GET /api/v1/services/environments", status, err.Error())
+				}
+			})
+
+			r.Post("/environments", func(w http.ResponseWriter, r *http.Request) {
+				status, err := services.APIAddEnvironment(w, r, db, gql, railwayConfig)
+				if err != nil {
+					errors.HandleAPIError(w, "POST /api/v1/services/environments", status, err.Error())
+				}
+			})
+
+			r.Delete("/environments/{environmentID}", func(w http.ResponseWriter, r *http.Request) {
+				status, err := services.APIRemoveEnvironment(w, r, db, railwayConfig)
+				if err != nil {
+					errors.HandleAPIError(w, "DELETE /api/v1/services/environments/{environmentID}", status, err.Error())
+				}
+			})
+		})
+
+		r.Route("/pipelines", func(r chi.Router) {
+			r.Use(middleware.RequireAPIPermission(types.Permission.CanManageForwarding))
+
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := forwarding.APIList(w, r, logForwarder)
+				if err != nil {
+					errors.HandleAPIError(w, "GET /api/v1/pipelines", status, err.Error())
+				}
+			})
+
+			r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := forwarding.APICreate(w, r, db, logForwarder)
+				if err != nil {
+					errors.HandleAPIError(w, "POST /api/v1/pipelines", status, err.Error())
+				}
+			})
+
+			r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+				status, err := forwarding.APIGet(w, r, db)
+				if err != nil {
+					errors.HandleAPIError(w, "GET /api/v1/pipelines/{id}", status, err.Error())
+				}
+			})
+
+			r.Put("/{id}", func(w http.ResponseWriter, r *http.Request) {
+				status, err := forwarding.APIUpdate(w, r, db, logForwarder)
+				if err != nil {
+					errors.HandleAPIError(w, "PUT /api/v1/pipelines/{id}", status, err.Error())
+				}
+			})
+
+			r.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
+				status, err := forwarding.APIDelete(w, r, db, logForwarder)
+				if err != nil {
+					errors.HandleAPIError(w, "DELETE /api/v1/pipelines/{id}", status, err.Error())
+				}
+			})
+		})
+
+		r.Route("/users", func(r chi.Router) {
+			r.Use(middleware.RequireAPIPermission(types.Permission.IsAdmin))
+
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := users.APIList(w, r, db)
+				if err != nil {
+					errors.HandleAPIError(w, "GET /api/v1/users", status, err.Error())
+				}
+			})
+
+			r.Put("/{id}/permissions", func(w http.ResponseWriter, r *http.Request) {
+				status, err := users.APIUpdatePermissions(w, r, db)
+				if err != nil {
+					errors.HandleAPIError(w, "PUT /api/v1/users/{id}/permissions", status, err.Error())
+				}
+			})
+
+			r.Put("/{id}/password", func(w http.ResponseWriter, r *http.Request) {
+				status, err := users.APIResetPassword(w, r, db, sessionManager)
+				if err != nil {
+					errors.HandleAPIError(w, "PUT /api/v1/users/{id}/password", status, err.Error())
+				}
+			})
+
+			r.Delete("/{id}", func(w http.ResponseWriter, r *http.Request) {
+				status, err := users.APIDelete(w, r, db, sessionManager)
+				if err != nil {
+					errors.HandleAPIError(w, "DELETE /api/v1/users/{id}", status, err.Error())
+				}
+			})
+		})
+
+		r.Route("/logs", func(r chi.Router) {
+			r.Use(middleware.RequireAPIPermission(types.Permission.CanViewLogs))
+
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := logs.APIQuery(w, r, logStore)
+				if err != nil {
+					errors.HandleAPIError(w, "GET /api/v1/logs", status, err.Error())
+				}
+			})
+		})
+	})
+}
@@ -0,0 +1,48 @@
+// Package api holds the helpers shared by the json handlers of /api/v1
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+)
+
+// maxBodySize limits request bodies, nothing the api accepts comes close
+const maxBodySize = 1 << 20
+
+// WriteJSON writes v with the given status. A nil v writes no body
+func WriteJSON(w http.ResponseWriter, status int, v any) error {
+	if v == nil {
+		w.WriteHeader(status)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	return json.NewEncoder(w).Encode(v)
+}
+
+// DecodeJSON reads the request body into v. Fields already set on v are kept
+// when the body leaves them out.
+//
+// Requiring the json content type also means a browser can't send the request
+// cross origin without a CORS preflight, which matters while the api accepts
+// the dashboard session cookie
+func DecodeJSON(r *http.Request, v any) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return errors.New("the request body must be application/json")
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+
+	err = decoder.Decode(v)
+	if err != nil {
+		return errors.New("invalid json body: " + err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/jmoiron/sqlx"
+)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type appliedMigration struct {
+	Version   int       `db:"version"`
+	Name      string    `db:"name"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+func createSchemaMigrationsTable(db *sqlx.DB) error {
+	createSchemaMigrationsQuery := `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME NOT NULL
+	);
+	`
+
+	_, err := db.Exec(createSchemaMigrationsQuery)
+	return err
+}
+
+func getAppliedMigrations(db *sqlx.DB) (map[int]appliedMigration, error) {
+	err := createSchemaMigrationsTable(db)
+	if err != nil {
+		return nil, err
+	}
+
+	selectAppliedQuery := squirrel.
+		Select("version", "name", "applied_at").
+		From("schema_migrations")
+
+	sql, args, err := selectAppliedQuery.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []appliedMigration{}
+
+	err = db.Select(&rows, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]appliedMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
+
+// MigrateUp applies every pending migration in order. Each migration runs in
+// its own transaction, so a failure leaves the earlier migrations applied
+func MigrateUp(db *sqlx.DB) (applied []Migration, err error) {
+	alreadyApplied, err := getAppliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range migrations {
+		if _, ok := alreadyApplied[migration.Version]; ok {
+			continue
+		}
+
+		err = runMigration(db, migration.Up, func(tx *sqlx.Tx) error {
+			insertAppliedQuery := squirrel.
+				Insert("schema_migrations").
+				Columns("version", "name", "applied_at").
+				Values(migration.Version, migration.Name, time.Now().UTC())
+
+			sql, args, err := insertAppliedQuery.ToSql()
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.Exec(sql, args...)
+			return err
+		})
+		if err != nil {
+			return applied, fmt.Errorf("error applying migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+
+		log.Info("applied migration", "version", migration.Version, "name", migration.Name)
+
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+// MigrateDown reverts the latest steps applied migrations, newest first
+func MigrateDown(db *sqlx.DB, steps int) (reverted []Migration, err error) {
+	if steps < 1 {
+		return nil, errors.New("steps must be at least 1")
+	}
+
+	alreadyApplied, err := getAppliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration := migrations[i]
+
+		if _, ok := alreadyApplied[migration.Version]; !ok {
+			continue
+		}
+
+		err = runMigration(db, migration.Down, func(tx *sqlx.Tx) error {
+			deleteAppliedQuery := squirrel.
+				Delete("schema_migrations").
+				Where(squirrel.Eq{"version": migration.Version})
+
+			sql, args, err := deleteAppliedQuery.ToSql()
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.Exec(sql, args...)
+			return err
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("error reverting migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+
+		log.Info("reverted migration", "version", migration.Version, "name", migration.Name)
+
+		reverted = append(reverted, migration)
+	}
+
+	return reverted, nil
+}
+
+func GetMigrationStatus(db *sqlx.DB) ([]MigrationStatus, error) {
+	alreadyApplied, err := getAppliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, len(migrations))
+
+	for i, migration := range migrations {
+		applied, ok := alreadyApplied[migration.Version]
+
+		status[i] = MigrationStatus{
+			Migration: migration,
+			Applied:   ok,
+			AppliedAt: applied.AppliedAt,
+		}
+	}
+
+	return status, nil
+}
+
+func runMigration(db *sqlx.DB, statements string, record func(tx *sqlx.Tx) error) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(statements)
+	if err != nil {
+		return err
+	}
+
+	err = record(tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
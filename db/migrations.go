@@ -1,45 +1,280 @@
 package db
 
-import (
-	"github.com/jmoiron/sqlx"
-)
+// migrations are applied in order and must never be edited once released,
+// add a new migration instead.
+//
+// Databases created before versioned migrations already contain the tables
+// of migrations 1 to 5 without a schema_migrations row, which is why those
+// migrations use IF NOT EXISTS
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_users_and_permissions",
+		Up: `
+		CREATE TABLE IF NOT EXISTS User (
+			ID INTEGER PRIMARY KEY AUTOINCREMENT,
+			Username TEXT NOT NULL UNIQUE,
+			Password TEXT NOT NULL,
+			PermissionID INTEGER,
+			FOREIGN KEY (PermissionID) REFERENCES Permission(ID)
+		);
 
-func RunMigrations(db *sqlx.DB) error {
-	createUserTableQuery := `
-	CREATE TABLE User (
-		ID INTEGER PRIMARY KEY AUTOINCREMENT,
-		Username TEXT NOT NULL UNIQUE,
-		Password TEXT NOT NULL,
-		PermissionID INTEGER,
-		FOREIGN KEY (PermissionID) REFERENCES Permission(ID)
-	);`
+		CREATE TABLE IF NOT EXISTS Permission (
+			ID INTEGER PRIMARY KEY AUTOINCREMENT,
+			UserID INTEGER NOT NULL,
+			Admin BOOLEAN NOT NULL DEFAULT 0,
+			ManageServices BOOLEAN NOT NULL DEFAULT 0,
+			ManageTags BOOLEAN NOT NULL DEFAULT 0,
+			ManageForwarding BOOLEAN NOT NULL DEFAULT 0,
+			ViewLogs BOOLEAN NOT NULL DEFAULT 0,
+			FOREIGN KEY (UserID) REFERENCES User(ID) ON DELETE CASCADE
+		);
+		`,
+		Down: `
+		DROP TABLE Permission;
+		DROP TABLE User;
+		`,
+	},
+	{
+		Version: 2,
+		Name:    "create_tags",
+		Up: `
+		CREATE TABLE IF NOT EXISTS Tag (
+			ID INTEGER PRIMARY KEY AUTOINCREMENT,
+			Name TEXT NOT NULL UNIQUE,
+			Keyword TEXT NOT NULL DEFAULT '',
+			AttributeKey TEXT NOT NULL DEFAULT '',
+			AttributeValue TEXT NOT NULL DEFAULT '',
+			ServiceID TEXT NOT NULL DEFAULT ''
+		);
+		`,
+		Down: `
+		DROP TABLE Tag;
+		`,
+	},
+	{
+		Version: 3,
+		Name:    "create_services",
+		Up: `
+		CREATE TABLE IF NOT EXISTS Service (
+			ID INTEGER PRIMARY KEY AUTOINCREMENT,
+			ServiceID TEXT NOT NULL UNIQUE,
+			Name TEXT NOT NULL DEFAULT ''
+		);
+		`,
+		Down: `
+		DROP TABLE Service;
+		`,
+	},
+	{
+		Version: 4,
+		Name:    "create_pipelines",
+		Up: `
+		CREATE TABLE IF NOT EXISTS Pipeline (
+			ID INTEGER PRIMARY KEY AUTOINCREMENT,
+			Name TEXT NOT NULL UNIQUE,
+			URL TEXT NOT NULL,
+			Template TEXT NOT NULL,
+			TagID INTEGER NOT NULL DEFAULT 0,
+			Enabled BOOLEAN NOT NULL DEFAULT 1
+		);
+		`,
+		Down: `
+		DROP TABLE Pipeline;
+		`,
+	},
+	{
+		Version: 5,
+		Name:    "create_logs",
+		Up: `
+		CREATE TABLE IF NOT EXISTS Log (
+			ID INTEGER PRIMARY KEY AUTOINCREMENT,
+			Timestamp INTEGER NOT NULL,
+			Level TEXT NOT NULL DEFAULT '',
+			Message TEXT NOT NULL DEFAULT '',
+			ServiceID TEXT NOT NULL DEFAULT '',
+			ServiceName TEXT NOT NULL DEFAULT '',
+			EnvironmentID TEXT NOT NULL DEFAULT '',
+			EnvironmentName TEXT NOT NULL DEFAULT '',
+			ProjectID TEXT NOT NULL DEFAULT '',
+			ProjectName TEXT NOT NULL DEFAULT '',
+			DeploymentID TEXT NOT NULL DEFAULT '',
+			DeploymentInstanceID TEXT NOT NULL DEFAULT '',
+			Attributes TEXT NOT NULL DEFAULT '{}',
+			Raw TEXT NOT NULL DEFAULT '{}'
+		);
+		CREATE INDEX IF NOT EXISTS LogTimestamp ON Log (Timestamp, ID);
+		CREATE INDEX IF NOT EXISTS LogLevel ON Log (Level, Timestamp, ID);
+		CREATE INDEX IF NOT EXISTS LogServiceID ON Log (ServiceID, Timestamp, ID);
 
-	createPermissionsQuery := `
-	CREATE TABLE Permission (
-		ID INTEGER PRIMARY KEY AUTOINCREMENT,
-		UserID INTEGER NOT NULL,
-		Admin BOOLEAN NOT NULL DEFAULT 0,
-		ManageServices BOOLEAN NOT NULL DEFAULT 0,
-		ManageTags BOOLEAN NOT NULL DEFAULT 0,
-		ManageForwarding BOOLEAN NOT NULL DEFAULT 0,
-		ViewLogs BOOLEAN NOT NULL DEFAULT 0,
-		FOREIGN KEY (UserID) REFERENCES User(ID) ON DELETE CASCADE
-	);
-	`
-
-	var errors []error
-
-	_, err := db.Query(createUserTableQuery)
-	errors = append(errors, err)
-
-	_, err = db.Query(createPermissionsQuery)
-	errors = append(errors, err)
-
-	for _, err := range errors {
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+		CREATE TABLE IF NOT EXISTS LogTag (
+			LogID INTEGER NOT NULL,
+			Tag TEXT NOT NULL,
+			Timestamp INTEGER NOT NULL,
+			PRIMARY KEY (Tag, Timestamp, LogID),
+			FOREIGN KEY (LogID) REFERENCES Log(ID) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS LogTagLogID ON LogTag (LogID);
+		`,
+		Down: `
+		DROP TABLE LogTag;
+		DROP TABLE Log;
+		`,
+	},
+	{
+		Version: 6,
+		Name:    "create_sessions",
+		Up: `
+		CREATE TABLE Session (
+			ID TEXT PRIMARY KEY,
+			UserID INTEGER NOT NULL,
+			ExpiresAt INTEGER NOT NULL,
+			FOREIGN KEY (UserID) REFERENCES User(ID) ON DELETE CASCADE
+		);
+		CREATE INDEX SessionExpiresAt ON Session (ExpiresAt);
+		`,
+		Down: `
+		DROP TABLE Session;
+		`,
+	},
+	{
+		Version: 7,
+		Name:    "create_invites",
+		Up: `
+		CREATE TABLE Invite (
+			ID INTEGER PRIMARY KEY AUTOINCREMENT,
+			TokenHash TEXT NOT NULL UNIQUE,
+			Admin BOOLEAN NOT NULL DEFAULT 0,
+			ManageServices BOOLEAN NOT NULL DEFAULT 0,
+			ManageTags BOOLEAN NOT NULL DEFAULT 0,
+			ManageForwarding BOOLEAN NOT NULL DEFAULT 0,
+			ViewLogs BOOLEAN NOT NULL DEFAULT 0,
+			CreatedBy INTEGER NOT NULL,
+			CreatedAt INTEGER NOT NULL,
+			ExpiresAt INTEGER NOT NULL,
+			UsedBy INTEGER NOT NULL DEFAULT 0,
+			UsedAt INTEGER NOT NULL DEFAULT 0
+		);
+		`,
+		Down: `
+		DROP TABLE Invite;
+		`,
+	},
+	{
+		Version: 8,
+		Name:    "create_api_tokens",
+		Up: `
+		CREATE TABLE APIToken (
+			ID INTEGER PRIMARY KEY AUTOINCREMENT,
+			UserID INTEGER NOT NULL,
+			Name TEXT NOT NULL,
+			TokenHash TEXT NOT NULL UNIQUE,
+			Admin BOOLEAN NOT NULL DEFAULT 0,
+			ManageServices BOOLEAN NOT NULL DEFAULT 0,
+			ManageTags BOOLEAN NOT NULL DEFAULT 0,
+			ManageForwarding BOOLEAN NOT NULL DEFAULT 0,
+			ViewLogs BOOLEAN NOT NULL DEFAULT 0,
+			CreatedAt INTEGER NOT NULL,
+			ExpiresAt INTEGER NOT NULL DEFAULT 0,
+			LastUsedAt INTEGER NOT NULL DEFAULT 0,
+			FOREIGN KEY (UserID) REFERENCES User(ID) ON DELETE CASCADE
+		);
+		CREATE INDEX APITokenUserID ON APIToken (UserID);
+		`,
+		Down: `
+		DROP TABLE APIToken;
+		`,
+	},
+	{
+		Version: 9,
+		Name:    "create_ingest_sources",
+		Up: `
+		CREATE TABLE IngestSource (
+			ID INTEGER PRIMARY KEY AUTOINCREMENT,
+			Name TEXT NOT NULL UNIQUE,
+			KeyHash TEXT NOT NULL UNIQUE,
+			MessageKey TEXT NOT NULL DEFAULT '',
+			LevelKey TEXT NOT NULL DEFAULT '',
+			TimestampKey TEXT NOT NULL DEFAULT '',
+			CreatedAt INTEGER NOT NULL
+		);
+		`,
+		Down: `
+		DROP TABLE IngestSource;
+		`,
+	},
+	{
+		Version: 10,
+		Name:    "add_pipeline_kind",
+		Up: `
+		ALTER TABLE Pipeline ADD COLUMN Kind TEXT NOT NULL DEFAULT 'webhook';
+		`,
+		Down: `
+		ALTER TABLE Pipeline DROP COLUMN Kind;
+		`,
+	},
+	{
+		Version: 11,
+		Name:    "add_pipeline_index_pattern",
+		Up: `
+		ALTER TABLE Pipeline ADD COLUMN IndexPattern TEXT NOT NULL DEFAULT '';
+		`,
+		Down: `
+		ALTER TABLE Pipeline DROP COLUMN IndexPattern;
+		`,
+	},
+	{
+		Version: 12,
+		Name:    "add_pipeline_rate_limit",
+		Up: `
+		ALTER TABLE Pipeline ADD COLUMN RateLimit INTEGER NOT NULL DEFAULT 0;
+		`,
+		Down: `
+		ALTER TABLE Pipeline DROP COLUMN RateLimit;
+		`,
+	},
+	{
+		Version: 13,
+		Name:    "create_railway_cursors",
+		Up: `
+		CREATE TABLE RailwayCursor (
+			EnvironmentID TEXT PRIMARY KEY,
+			Timestamp INTEGER NOT NULL,
+			DeploymentInstanceID TEXT NOT NULL DEFAULT '',
+			UpdatedAt INTEGER NOT NULL
+		);
+		`,
+		Down: `
+		DROP TABLE RailwayCursor;
+		`,
+	},
+	{
+		Version: 14,
+		Name:    "create_railway_environments",
+		Up: `
+		CREATE TABLE RailwayEnvironment (
+			ID INTEGER PRIMARY KEY AUTOINCREMENT,
+			EnvironmentID TEXT NOT NULL UNIQUE,
+			Name TEXT NOT NULL,
+			ProjectID TEXT NOT NULL,
+			ProjectName TEXT NOT NULL,
+			CreatedAt INTEGER NOT NULL
+		);
+		`,
+		Down: `
+		DROP TABLE RailwayEnvironment;
+		`,
+	},
+	{
+		Version: 15,
+		Name:    "add_railway_cursor_seen",
+		Up: `
+		ALTER TABLE RailwayCursor ADD COLUMN Seen TEXT NOT NULL DEFAULT '';
+		ALTER TABLE RailwayCursor DROP COLUMN DeploymentInstanceID;
+		`,
+		Down: `
+		ALTER TABLE RailwayCursor ADD COLUMN DeploymentInstanceID TEXT NOT NULL DEFAULT '';
+		ALTER TABLE RailwayCursor DROP COLUMN Seen;
+		`,
+	},
 }
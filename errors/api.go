@@ -0,0 +1,31 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/types"
+)
+
+// HandleAPIError is the json counterpart of HandleError for /api routes
+func HandleAPIError(w http.ResponseWriter, source string, status int, err string) {
+	log.Error(
+		fmt.Sprintf("error serving %s", source),
+		"err",
+		err,
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	serveErr := json.NewEncoder(w).Encode(types.Error{
+		Status: status,
+		Error:  err,
+	})
+
+	if serveErr != nil {
+		log.Error("error writing api error", "err", serveErr)
+	}
+}
@@ -0,0 +1,209 @@
+package forwarder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/types"
+)
+
+const (
+	// DefaultRateLimit is the messages per minute of a new chat pipeline
+	DefaultRateLimit = 10
+	MaxRateLimit     = 60
+
+	// chatLines bounds how many logs are written out in one message, the
+	// rest of the burst is only counted
+	chatLines        = 10
+	chatLineLength   = 300
+	discordMaxLength = 2000
+)
+
+// chatPreset formats a message for an incoming webhook flavour
+type chatPreset struct {
+	// escape is applied to every piece of log text
+	escape func(text string) string
+	bold   func(text string) string
+	link   func(text string, url string) string
+	body   func(text string) any
+	// maxLength bounds the text, 0 for no limit
+	maxLength int
+}
+
+var chatPresets = map[string]chatPreset{
+	KindSlack: {
+		escape: strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "`", "'").Replace,
+		bold:   func(text string) string { return "*" + text + "*" },
+		link:   func(text string, url string) string { return "<" + url + "|" + text + ">" },
+		body:   func(text string) any { return map[string]string{"text": text} },
+	},
+	KindDiscord: {
+		escape: strings.NewReplacer("`", "'").Replace,
+		bold:   func(text string) string { return "**" + text + "**" },
+		link:   func(text string, url string) string { return "[" + text + "](" + url + ")" },
+		body: func(text string) any {
+			return map[string]any{
+				"content": text,
+				// logs may contain @everyone or role mentions
+				"allowed_mentions": map[string]any{"parse": []string{}},
+			}
+		},
+		maxLength: discordMaxLength,
+	},
+}
+
+// chatLimit tracks the messages a pipeline sent in the last minute and the
+// logs it dropped since its last message
+type chatLimit struct {
+	sent       []time.Time
+	suppressed int
+}
+
+// sendChat posts a burst of logs as one chat message. Once the pipeline has
+// sent RateLimit messages in the last minute its logs are dropped, and the
+// next message says how many were
+func (f *Forwarder) sendChat(ctx context.Context, pipeline types.Pipeline, logs []types.Log) error {
+	f.chatMu.Lock()
+	limit, ok := f.chatLimits[pipeline.ID]
+	if !ok {
+		limit = &chatLimit{}
+		f.chatLimits[pipeline.ID] = limit
+	}
+	f.chatMu.Unlock()
+
+	now := time.Now()
+
+	recent := limit.sent[:0]
+	for _, sent := range limit.sent {
+		if now.Sub(sent) < time.Minute {
+			recent = append(recent, sent)
+		}
+	}
+	limit.sent = recent
+
+	if len(limit.sent) >= pipeline.RateLimit {
+		limit.suppressed += len(logs)
+		return nil
+	}
+
+	preset := chatPresets[pipeline.Kind]
+
+	body, err := json.Marshal(preset.body(chatMessage(preset, pipeline, logs, limit.suppressed)))
+	if err != nil {
+		return err
+	}
+
+	err = f.post(ctx, pipeline.URL, body, nil)
+	if err != nil {
+		return err
+	}
+
+	// only delivered messages count against the rate limit
+	limit.sent = append(limit.sent, now)
+
+	if limit.suppressed > 0 {
+		log.Info("chat pipeline was rate limited", "pipeline", pipeline.Name, "dropped", limit.suppressed)
+		limit.suppressed = 0
+	}
+
+	return nil
+}
+
+func chatMessage(preset chatPreset, pipeline types.Pipeline, logs []types.Log, suppressed int) string {
+	message := strings.Builder{}
+
+	noun := "logs"
+	if len(logs) == 1 {
+		noun = "log"
+	}
+
+	fmt.Fprintf(&message, "%s: %d %s\n", preset.bold(preset.escape(pipeline.Name)), len(logs), noun)
+
+	for i, newLog := range logs {
+		if i == chatLines {
+			fmt.Fprintf(&message, "…and %d more\n", len(logs)-chatLines)
+			break
+		}
+
+		service := newLog.ServiceName
+		if service == "" {
+			service = newLog.ServiceID
+		}
+
+		line := strings.Join(strings.Fields(newLog.Message), " ")
+		if len(line) > chatLineLength {
+			line = strings.ToValidUTF8(line[:chatLineLength], "") + "…"
+		}
+
+		fmt.Fprintf(&message, "%s %s `%s`\n", strings.ToUpper(newLog.Level), preset.escape(service), preset.escape(line))
+	}
+
+	if suppressed > 0 {
+		fmt.Fprintf(&message, "%d logs were dropped by the rate limit of %d messages per minute\n", suppressed, pipeline.RateLimit)
+	}
+
+	link := ""
+	if url := dashboardLink(logs); url != "" {
+		link = preset.link("View in pricetag", url)
+	}
+
+	// the log lines are cut short rather than the link, unless the link
+	// alone doesn't fit
+	text := []rune(message.String())
+
+	if preset.maxLength != 0 {
+		if len([]rune(link))+2 > preset.maxLength {
+			link = ""
+		}
+
+		if len(text)+len([]rune(link)) > preset.maxLength {
+			cut := min(max(preset.maxLength-len([]rune(link))-2, 0), len(text))
+			text = append(text[:cut], '…', '\n')
+		}
+	}
+
+	return string(text) + link
+}
+
+// dashboardLink links to the log history around a burst, narrowed to its
+// service when every log comes from the same one. It needs PUBLIC_URL, the
+// forwarder doesn't otherwise know where the dashboard is served
+func dashboardLink(logs []types.Log) string {
+	baseURL := strings.TrimSuffix(os.Getenv("PUBLIC_URL"), "/")
+	if baseURL == "" || len(logs) == 0 {
+		return ""
+	}
+
+	from, to := logs[0].Timestamp, logs[0].Timestamp
+	serviceID := logs[0].ServiceID
+
+	for _, newLog := range logs[1:] {
+		if newLog.Timestamp.Before(from) {
+			from = newLog.Timestamp
+		}
+
+		if newLog.Timestamp.After(to) {
+			to = newLog.Timestamp
+		}
+
+		if newLog.ServiceID != serviceID {
+			serviceID = ""
+		}
+	}
+
+	query := url.Values{}
+	query.Set("from", from.Add(-time.Second).UTC().Format(time.RFC3339))
+	query.Set("to", to.Add(time.Second).UTC().Format(time.RFC3339))
+
+	if serviceID != "" {
+		query.Set("service", serviceID)
+	}
+
+	return baseURL + "/dashboard/logs/history?" + query.Encode()
+}
@@ -0,0 +1,115 @@
+package forwarder
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ferretcode/pricetag/types"
+)
+
+const (
+	KindWebhook = "webhook"
+	KindLoki    = "loki"
+	// KindElasticsearch also covers OpenSearch, which has the same bulk api
+	KindElasticsearch = "elasticsearch"
+	// KindSlack and KindDiscord post bursts of logs to incoming webhooks of
+	// Slack or Discord, or any chat accepting the same payload
+	KindSlack   = "slack"
+	KindDiscord = "discord"
+
+	maxAttempts = 3
+	retryDelay  = time.Second
+)
+
+// Kinds lists every pipeline kind, in the order they are shown to users
+var Kinds = []string{
+	KindWebhook,
+	KindLoki,
+	KindElasticsearch,
+	KindSlack,
+	KindDiscord,
+}
+
+// kind describes how a pipeline delivers logs. Logs are collected until the
+// batch holds maxBatch logs or is maxAge old, then passed to send
+type kind struct {
+	maxBatch int
+	maxAge   time.Duration
+	send     func(f *Forwarder, ctx context.Context, pipeline types.Pipeline, logs []types.Log) error
+}
+
+var kinds = map[string]kind{
+	KindWebhook: {
+		maxBatch: 1,
+		send:     (*Forwarder).sendWebhook,
+	},
+	KindLoki: {
+		maxBatch: 1000,
+		maxAge:   2 * time.Second,
+		send:     (*Forwarder).sendLoki,
+	},
+	KindElasticsearch: {
+		maxBatch: 500,
+		maxAge:   5 * time.Second,
+		send:     (*Forwarder).sendElasticsearch,
+	},
+	// a burst of logs within maxAge becomes one message
+	KindSlack: {
+		maxBatch: 50,
+		maxAge:   5 * time.Second,
+		send:     (*Forwarder).sendChat,
+	},
+	KindDiscord: {
+		maxBatch: 50,
+		maxAge:   5 * time.Second,
+		send:     (*Forwarder).sendChat,
+	},
+}
+
+type batch struct {
+	pipeline types.Pipeline
+	logs     []types.Log
+	started  time.Time
+}
+
+// permanentError marks an error that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+func permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// retry calls fn up to maxAttempts times, waiting longer after each failure
+func retry(ctx context.Context, fn func() error) error {
+	var err error
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = fn()
+		if err == nil || errors.As(err, &permanentError{}) {
+			return err
+		}
+
+		if attempt == maxAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(retryDelay * time.Duration(attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return err
+}
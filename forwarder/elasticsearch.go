@@ -0,0 +1,268 @@
+package forwarder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/types"
+)
+
+// DefaultIndexPattern writes one index per service and day
+const DefaultIndexPattern = "pricetag-{service}-{yyyy.MM.dd}"
+
+// indexFields are the log fields an index pattern may use, anything else in
+// braces is a date format
+var indexFields = map[string]func(log types.Log) string{
+	"service":     func(log types.Log) string { return log.ServiceName },
+	"environment": func(log types.Log) string { return log.EnvironmentName },
+	"project":     func(log types.Log) string { return log.ProjectName },
+	"level":       func(log types.Log) string { return log.Level },
+}
+
+// dateTokens maps the date tokens of an index pattern, as used by
+// Elasticsearch and OpenSearch, to go layouts. Longer tokens come first
+var dateTokens = []string{
+	"yyyy", "2006",
+	"yy", "06",
+	"MM", "01",
+	"dd", "02",
+	"HH", "15",
+}
+
+// indexNameReplacer removes the characters Elasticsearch doesn't allow in
+// index names
+var indexNameReplacer = strings.NewReplacer(
+	`\`, "-", "/", "-", "*", "-", "?", "-", `"`, "-", "<", "-", ">", "-",
+	"|", "-", " ", "-", ",", "-", "#", "-", ":", "-",
+)
+
+type bulkAction struct {
+	Index bulkIndex `json:"index"`
+}
+
+type bulkIndex struct {
+	Index string `json:"_index"`
+}
+
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []struct {
+		Index struct {
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"index"`
+	} `json:"items"`
+}
+
+type bulkDocument struct {
+	index string
+	body  []byte
+}
+
+// sendElasticsearch indexes a batch with the _bulk api of the pipeline url,
+// the base url of the cluster. Documents that fail with 429 or a 5xx status
+// are sent again on their own, other failures are logged and dropped
+func (f *Forwarder) sendElasticsearch(ctx context.Context, pipeline types.Pipeline, logs []types.Log) error {
+	documents := make([]bulkDocument, 0, len(logs))
+
+	for _, newLog := range logs {
+		body, err := bulkDocumentBody(newLog)
+		if err != nil {
+			log.Warn("skipping log that isn't a json object", "pipeline", pipeline.Name, "err", err)
+			continue
+		}
+
+		documents = append(documents, bulkDocument{
+			index: IndexName(pipeline.IndexPattern, newLog),
+			body:  body,
+		})
+	}
+
+	// an empty bulk request is rejected
+	if len(documents) == 0 {
+		return nil
+	}
+
+	url := strings.TrimSuffix(pipeline.URL, "/") + "/_bulk"
+
+	for attempt := 1; ; attempt++ {
+		failed, err := f.bulk(ctx, url, documents)
+		if err != nil {
+			return err
+		}
+
+		if len(failed) == 0 {
+			return nil
+		}
+
+		if attempt == maxAttempts {
+			return fmt.Errorf("%d documents could not be indexed", len(failed))
+		}
+
+		select {
+		case <-time.After(retryDelay * time.Duration(attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		documents = failed
+	}
+}
+
+// bulk sends one bulk request and returns the documents worth retrying
+func (f *Forwarder) bulk(ctx context.Context, url string, documents []bulkDocument) ([]bulkDocument, error) {
+	body := &bytes.Buffer{}
+
+	for _, document := range documents {
+		action, err := json.Marshal(bulkAction{
+			Index: bulkIndex{Index: document.index},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(document.body)
+		body.WriteByte('\n')
+	}
+
+	response, err := f.request(ctx, url, body.Bytes(), map[string]string{
+		"Content-Type": "application/x-ndjson",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bulkResponse := bulkResponse{}
+
+	err = json.Unmarshal(response, &bulkResponse)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bulk response: %w", err)
+	}
+
+	if !bulkResponse.Errors {
+		return nil, nil
+	}
+
+	if len(bulkResponse.Items) != len(documents) {
+		return nil, errors.New("the bulk response doesn't match the request")
+	}
+
+	failed := []bulkDocument{}
+
+	for i, item := range bulkResponse.Items {
+		status := item.Index.Status
+
+		switch {
+		case status < 300:
+		case status == 429 || status >= 500:
+			failed = append(failed, documents[i])
+		default:
+			log.Error("document was rejected", "index", documents[i].index, "status", status, "err", string(item.Index.Error))
+		}
+	}
+
+	return failed, nil
+}
+
+// bulkDocumentBody is the reconstructed json log line, with an @timestamp
+// added for index templates and dashboards that expect one
+func bulkDocumentBody(newLog types.Log) ([]byte, error) {
+	// Raw is shared with the other pipelines and jsonparser.Set may write
+	// into its backing array
+	body := bytes.Clone(newLog.Raw)
+	if len(body) == 0 {
+		body = []byte(`{"message":` + strconv.Quote(newLog.Message) + `}`)
+	}
+
+	if _, _, _, err := jsonparser.Get(body, "@timestamp"); err == nil {
+		return body, nil
+	}
+
+	return jsonparser.Set(body, []byte(strconv.Quote(newLog.Timestamp.Format(time.RFC3339Nano))), "@timestamp")
+}
+
+// IndexName fills in the index pattern for a log. Fields become lowercase,
+// as index names must be, and dates are formatted in UTC
+func IndexName(pattern string, newLog types.Log) string {
+	name := strings.Builder{}
+
+	for {
+		start := strings.IndexByte(pattern, '{')
+		end := strings.IndexByte(pattern, '}')
+		if start == -1 || end < start {
+			name.WriteString(pattern)
+			break
+		}
+
+		name.WriteString(pattern[:start])
+
+		token := pattern[start+1 : end]
+		if field, ok := indexFields[token]; ok {
+			value := field(newLog)
+			if value == "" {
+				value = "unknown"
+			}
+			name.WriteString(indexNameReplacer.Replace(value))
+		} else {
+			name.WriteString(newLog.Timestamp.UTC().Format(strings.NewReplacer(dateTokens...).Replace(token)))
+		}
+
+		pattern = pattern[end+1:]
+	}
+
+	return strings.ToLower(name.String())
+}
+
+// ValidateIndexPattern checks that every placeholder is a known field or a
+// date format
+func ValidateIndexPattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return errors.New("your index pattern cannot be empty")
+	}
+
+	rest := pattern
+
+	for {
+		start := strings.IndexByte(rest, '{')
+		end := strings.IndexByte(rest, '}')
+
+		if start == -1 && end == -1 {
+			return nil
+		}
+
+		if start == -1 || end < start {
+			return errors.New("your index pattern has unbalanced braces")
+		}
+
+		token := rest[start+1 : end]
+
+		if _, ok := indexFields[token]; !ok && !isDateFormat(token) {
+			return fmt.Errorf("{%s} is not a field or a date format", token)
+		}
+
+		rest = rest[end+1:]
+	}
+}
+
+func isDateFormat(token string) bool {
+	if token == "" {
+		return false
+	}
+
+	remaining := token
+	for i := 0; i < len(dateTokens); i += 2 {
+		remaining = strings.ReplaceAll(remaining, dateTokens[i], "")
+	}
+
+	return strings.Trim(remaining, ".-_") == ""
+}
@@ -0,0 +1,96 @@
+package forwarder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ferretcode/pricetag/types"
+)
+
+func TestIndexName(t *testing.T) {
+	sample := types.Log{
+		ServiceName:     "Billing API",
+		EnvironmentName: "production",
+		ProjectName:     "shop/eu",
+		Level:           "error",
+		// 23:30 on the 9th in UTC-5 is the 10th in UTC
+		Timestamp: time.Date(2024, time.March, 9, 23, 30, 0, 0, time.FixedZone("", -5*60*60)),
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		log     types.Log
+		want    string
+	}{
+		{
+			name:    "default pattern",
+			pattern: DefaultIndexPattern,
+			log:     sample,
+			want:    "pricetag-billing-api-2024.03.10",
+		},
+		{
+			name:    "every field",
+			pattern: "{project}_{environment}_{service}_{level}",
+			log:     sample,
+			want:    "shop-eu_production_billing-api_error",
+		},
+		{
+			name:    "date tokens",
+			pattern: "logs-{yy}-{MM}-{dd}-{HH}",
+			log:     sample,
+			want:    "logs-24-03-10-04",
+		},
+		{
+			name:    "empty fields",
+			pattern: "logs-{service}",
+			log:     types.Log{},
+			want:    "logs-unknown",
+		},
+		{
+			name:    "no placeholders",
+			pattern: "Logs",
+			log:     sample,
+			want:    "logs",
+		},
+		{
+			name:    "unbalanced braces are kept",
+			pattern: "logs}-{service",
+			log:     sample,
+			want:    "logs}-{service",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IndexName(test.pattern, test.log); got != test.want {
+				t.Errorf("IndexName() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateIndexPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		wantErr bool
+	}{
+		{name: "default pattern", pattern: DefaultIndexPattern},
+		{name: "date format", pattern: "logs-{yyyy-MM}"},
+		{name: "no placeholders", pattern: "logs"},
+		{name: "empty", pattern: " ", wantErr: true},
+		{name: "unknown field", pattern: "logs-{host}", wantErr: true},
+		{name: "empty placeholder", pattern: "logs-{}", wantErr: true},
+		{name: "unbalanced braces", pattern: "logs-{service", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateIndexPattern(test.pattern)
+			if (err != nil) != test.wantErr {
+				t.Errorf("ValidateIndexPattern() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,352 @@
+package forwarder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/matcher"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	queueSize = 1000
+	// flushInterval is how often batches are checked against their max age
+	flushInterval   = time.Second
+	shutdownTimeout = 5 * time.Second
+	// workerQueue is how many batches a pipeline may have waiting to be
+	// sent before new ones are dropped
+	workerQueue = 16
+)
+
+// Forwarder sends logs from the sink to every enabled pipeline
+type Forwarder struct {
+	db         *sqlx.DB
+	tagMatcher *matcher.Matcher
+	client     *http.Client
+	queue      chan []types.Log
+	// batches holds the logs waiting to be sent, by pipeline id. It is only
+	// used by the Run goroutine
+	batches map[int]*batch
+	// workers send the full batches, by pipeline id. It is only used by the
+	// Run goroutine
+	workers map[int]*worker
+	// missingTags holds the pipelines already warned about forwarding by a
+	// tag that no longer exists. It is only used by the Run goroutine
+	missingTags map[int]bool
+
+	// chatLimits rate limits chat pipelines, by pipeline id. A limit is only
+	// used by the worker of its pipeline
+	chatMu     sync.Mutex
+	chatLimits map[int]*chatLimit
+
+	mu        sync.RWMutex
+	pipelines []types.Pipeline
+}
+
+func NewForwarder(db *sqlx.DB, tagMatcher *matcher.Matcher) (*Forwarder, error) {
+	forwarder := &Forwarder{
+		db:          db,
+		tagMatcher:  tagMatcher,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		queue:       make(chan []types.Log, queueSize),
+		batches:     map[int]*batch{},
+		workers:     map[int]*worker{},
+		missingTags: map[int]bool{},
+		chatLimits:  map[int]*chatLimit{},
+	}
+
+	if err := forwarder.Reload(); err != nil {
+		return nil, err
+	}
+
+	return forwarder, nil
+}
+
+// Reload must be called after the Pipeline table is modified
+func (f *Forwarder) Reload() error {
+	selectPipelinesQuery := squirrel.
+		Select("*").
+		From("Pipeline").
+		OrderBy("Name")
+
+	sql, args, err := selectPipelinesQuery.ToSql()
+	if err != nil {
+		return err
+	}
+
+	pipelines := []types.Pipeline{}
+
+	err = f.db.Select(&pipelines, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.pipelines = pipelines
+	f.mu.Unlock()
+
+	return nil
+}
+
+func (f *Forwarder) Pipelines() []types.Pipeline {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	pipelines := make([]types.Pipeline, len(f.pipelines))
+	copy(pipelines, f.pipelines)
+
+	return pipelines
+}
+
+// Handle is a sink.Handler. Batches are dropped rather than blocking the sink
+// when the forwarder falls behind
+func (f *Forwarder) Handle(logs []types.Log) {
+	select {
+	case f.queue <- logs:
+	default:
+		log.Warn("forwarding queue is full, dropping logs", "count", len(logs))
+	}
+}
+
+// Run batches logs until ctx is done. The batches are sent by a worker per
+// pipeline, so a slow destination only holds up its own pipeline
+func (f *Forwarder) Run(ctx context.Context) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	// the sends outlive ctx, so what is left is still sent on shutdown
+	sendCtx, cancelSends := context.WithCancel(context.Background())
+	defer cancelSends()
+
+	for {
+		select {
+		case <-ctx.Done():
+			f.flushAll(sendCtx)
+			f.stopWorkers(cancelSends)
+			return
+		case logs := <-f.queue:
+			f.forward(sendCtx, logs)
+		case <-ticker.C:
+			f.flushDue(sendCtx, time.Now())
+			f.stopRemovedWorkers()
+		}
+	}
+}
+
+// forward adds the logs matched by each enabled pipeline to its batch
+func (f *Forwarder) forward(ctx context.Context, logs []types.Log) {
+	tagNames := map[int]string{}
+	for _, tag := range f.tagMatcher.Tags() {
+		tagNames[tag.ID] = tag.Name
+	}
+
+	for _, pipeline := range f.Pipelines() {
+		if !pipeline.Enabled {
+			continue
+		}
+
+		if pipeline.TagID != 0 {
+			if _, ok := tagNames[pipeline.TagID]; !ok {
+				if !f.missingTags[pipeline.ID] {
+					log.Warn("pipeline forwards by a tag that no longer exists, nothing is forwarded until it is edited", "pipeline", pipeline.Name, "tag", pipeline.TagID)
+					f.missingTags[pipeline.ID] = true
+				}
+
+				continue
+			}
+
+			delete(f.missingTags, pipeline.ID)
+		}
+
+		for _, newLog := range logs {
+			if pipeline.TagID != 0 && !slices.Contains(newLog.Tags, tagNames[pipeline.TagID]) {
+				continue
+			}
+
+			f.add(ctx, pipeline, newLog)
+		}
+	}
+}
+
+func (f *Forwarder) add(ctx context.Context, pipeline types.Pipeline, newLog types.Log) {
+	kind, ok := kinds[pipeline.Kind]
+	if !ok {
+		log.Error("pipeline has an unknown kind", "pipeline", pipeline.Name, "kind", pipeline.Kind)
+		return
+	}
+
+	pending, ok := f.batches[pipeline.ID]
+	if !ok {
+		pending = &batch{
+			started: time.Now(),
+		}
+		f.batches[pipeline.ID] = pending
+	}
+
+	// an edited pipeline sends the rest of its batch with the new settings
+	pending.pipeline = pipeline
+	pending.logs = append(pending.logs, newLog)
+
+	if len(pending.logs) >= kind.maxBatch {
+		f.flush(ctx, pipeline.ID)
+	}
+}
+
+// flushDue sends the batches that are older than the max age of their kind
+func (f *Forwarder) flushDue(ctx context.Context, now time.Time) {
+	for id, pending := range f.batches {
+		if now.Sub(pending.started) >= kinds[pending.pipeline.Kind].maxAge {
+			f.flush(ctx, id)
+		}
+	}
+}
+
+// flushAll hands what is left to the workers on shutdown
+func (f *Forwarder) flushAll(ctx context.Context) {
+	for id := range f.batches {
+		f.flush(ctx, id)
+	}
+}
+
+// flush hands a batch to the worker of its pipeline. A pipeline that is
+// too far behind drops it rather than holding up the others
+func (f *Forwarder) flush(ctx context.Context, id int) {
+	pending := f.batches[id]
+	delete(f.batches, id)
+
+	w, ok := f.workers[id]
+	if !ok {
+		w = f.startWorker(ctx)
+		f.workers[id] = w
+	}
+
+	select {
+	case w.batches <- pending:
+	default:
+		log.Warn("pipeline is falling behind, dropping logs", "pipeline", pending.pipeline.Name, "count", len(pending.logs))
+	}
+}
+
+// worker sends the batches of one pipeline in order
+type worker struct {
+	batches chan *batch
+	done    chan struct{}
+}
+
+func (f *Forwarder) startWorker(ctx context.Context) *worker {
+	w := &worker{
+		batches: make(chan *batch, workerQueue),
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		defer close(w.done)
+
+		for pending := range w.batches {
+			err := kinds[pending.pipeline.Kind].send(f, ctx, pending.pipeline, pending.logs)
+			if err != nil {
+				log.Error("error forwarding logs", "pipeline", pending.pipeline.Name, "count", len(pending.logs), "err", err)
+			}
+		}
+	}()
+
+	return w
+}
+
+// stopRemovedWorkers stops the workers of deleted pipelines once they have
+// nothing left to send
+func (f *Forwarder) stopRemovedWorkers() {
+	pipelines := map[int]bool{}
+	for _, pipeline := range f.Pipelines() {
+		pipelines[pipeline.ID] = true
+	}
+
+	for id, w := range f.workers {
+		if _, pending := f.batches[id]; pending || pipelines[id] {
+			continue
+		}
+
+		close(w.batches)
+		delete(f.workers, id)
+
+		f.chatMu.Lock()
+		delete(f.chatLimits, id)
+		f.chatMu.Unlock()
+	}
+}
+
+// stopWorkers waits for the workers to send what they hold, for up to
+// shutdownTimeout before the sends are cancelled
+func (f *Forwarder) stopWorkers(cancelSends context.CancelFunc) {
+	for _, w := range f.workers {
+		close(w.batches)
+	}
+
+	timeout := time.NewTimer(shutdownTimeout)
+	defer timeout.Stop()
+
+	for id, w := range f.workers {
+		select {
+		case <-w.done:
+		case <-timeout.C:
+			cancelSends()
+			<-w.done
+		}
+
+		delete(f.workers, id)
+	}
+}
+
+// post sends a request body with retries. A 4xx response other than 429
+// means the request itself is wrong, so it is not retried
+func (f *Forwarder) post(ctx context.Context, url string, body []byte, headers map[string]string) error {
+	_, err := f.request(ctx, url, body, headers)
+	return err
+}
+
+// request is post, returning the body of the successful response
+func (f *Forwarder) request(ctx context.Context, url string, body []byte, headers map[string]string) ([]byte, error) {
+	var response []byte
+
+	err := retry(ctx, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return permanent(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		res, err := f.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode >= 300 {
+			err = fmt.Errorf("%s responded with status %d", req.URL.Host, res.StatusCode)
+
+			if res.StatusCode >= 400 && res.StatusCode < 500 && res.StatusCode != http.StatusTooManyRequests {
+				return permanent(err)
+			}
+
+			return err
+		}
+
+		response, err = io.ReadAll(res.Body)
+		return err
+	})
+
+	return response, err
+}
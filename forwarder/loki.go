@@ -0,0 +1,147 @@
+package forwarder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ferretcode/pricetag/types"
+)
+
+type lokiPush struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	// Values are [unix nanoseconds, line] pairs
+	Values [][2]string `json:"values"`
+}
+
+// sendLoki pushes a batch to the Loki push api, grouped into streams by
+// their labels. The pipeline url is the push endpoint, such as
+// http://loki:3100/loki/api/v1/push
+func (f *Forwarder) sendLoki(ctx context.Context, pipeline types.Pipeline, logs []types.Log) error {
+	// entries within a stream must be in time order
+	logs = slices.Clone(logs)
+	slices.SortStableFunc(logs, func(a, b types.Log) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	streams := map[string]*lokiStream{}
+	keys := []string{}
+
+	for _, newLog := range logs {
+		labels := lokiLabels(newLog)
+		key := lokiStreamKey(labels)
+
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{
+				Stream: labels,
+			}
+			streams[key] = stream
+			keys = append(keys, key)
+		}
+
+		line := string(newLog.Raw)
+		if line == "" {
+			line = newLog.Message
+		}
+
+		stream.Values = append(stream.Values, [2]string{
+			strconv.FormatInt(newLog.Timestamp.UnixNano(), 10),
+			line,
+		})
+	}
+
+	push := lokiPush{
+		Streams: make([]lokiStream, 0, len(keys)),
+	}
+
+	for _, key := range keys {
+		push.Streams = append(push.Streams, *streams[key])
+	}
+
+	body, err := json.Marshal(push)
+	if err != nil {
+		return err
+	}
+
+	compressed := &bytes.Buffer{}
+
+	writer := gzip.NewWriter(compressed)
+
+	_, err = writer.Write(body)
+	if err != nil {
+		return err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+
+	return f.post(ctx, pipeline.URL, compressed.Bytes(), map[string]string{
+		"Content-Encoding": "gzip",
+	})
+}
+
+// lokiLabels derives the stream labels of a log. Each matched tag becomes a
+// tag_<name>="true" label so streams can be selected by tag
+func lokiLabels(newLog types.Log) map[string]string {
+	labels := map[string]string{
+		"job": "pricetag",
+	}
+
+	if newLog.ServiceName != "" {
+		labels["service"] = newLog.ServiceName
+	}
+
+	if newLog.EnvironmentName != "" {
+		labels["environment"] = newLog.EnvironmentName
+	}
+
+	if newLog.Level != "" {
+		labels["level"] = newLog.Level
+	}
+
+	for _, tag := range newLog.Tags {
+		labels["tag_"+lokiLabelName(tag)] = "true"
+	}
+
+	return labels
+}
+
+// lokiLabelName replaces the characters Loki doesn't allow in label names
+func lokiLabelName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func lokiStreamKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	key := strings.Builder{}
+	for _, name := range names {
+		key.WriteString(name)
+		key.WriteByte('=')
+		key.WriteString(strconv.Quote(labels[name]))
+		key.WriteByte(',')
+	}
+
+	return key.String()
+}
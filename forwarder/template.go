@@ -0,0 +1,97 @@
+package forwarder
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ferretcode/pricetag/types"
+)
+
+// Variables lists every variable a pipeline template may use, in the order
+// they are shown to users
+var Variables = []string{
+	"$LOG_CONTENT",
+	"$LOG_LEVEL",
+	"$LOG_TIMESTAMP",
+	"$LOG_TAGS",
+	"$LOG_SERVICE_ID",
+	"$LOG_SERVICE_NAME",
+	"$LOG_ENVIRONMENT_ID",
+	"$LOG_ENVIRONMENT_NAME",
+	"$LOG_PROJECT_ID",
+	"$LOG_PROJECT_NAME",
+	"$LOG_DEPLOYMENT_ID",
+	"$LOG_DEPLOYMENT_INSTANCE_ID",
+	"$LOG_ATTRIBUTES",
+	"$LOG_JSON",
+}
+
+// Render substitutes the $LOG_* variables in a template. String variables are
+// json escaped without surrounding quotes so they can be placed inside a json
+// string, $LOG_ATTRIBUTES and $LOG_JSON expand to json objects
+func Render(template string, log types.Log) string {
+	attributes, err := json.Marshal(log.Attributes)
+	if err != nil || log.Attributes == nil {
+		attributes = []byte("{}")
+	}
+
+	raw := string(log.Raw)
+	if raw == "" {
+		raw = "{}"
+	}
+
+	// longer names must come before their prefixes, e.g. $LOG_DEPLOYMENT_ID
+	// is a prefix of $LOG_DEPLOYMENT_INSTANCE_ID
+	replacer := strings.NewReplacer(
+		"$LOG_DEPLOYMENT_INSTANCE_ID", escape(log.DeploymentInstanceID),
+		"$LOG_DEPLOYMENT_ID", escape(log.DeploymentID),
+		"$LOG_ENVIRONMENT_NAME", escape(log.EnvironmentName),
+		"$LOG_ENVIRONMENT_ID", escape(log.EnvironmentID),
+		"$LOG_PROJECT_NAME", escape(log.ProjectName),
+		"$LOG_PROJECT_ID", escape(log.ProjectID),
+		"$LOG_SERVICE_NAME", escape(log.ServiceName),
+		"$LOG_SERVICE_ID", escape(log.ServiceID),
+		"$LOG_ATTRIBUTES", string(attributes),
+		"$LOG_TIMESTAMP", escape(log.Timestamp.Format(time.RFC3339Nano)),
+		"$LOG_CONTENT", escape(log.Message),
+		"$LOG_LEVEL", escape(log.Level),
+		"$LOG_TAGS", escape(strings.Join(log.Tags, ",")),
+		"$LOG_JSON", raw,
+	)
+
+	return replacer.Replace(template)
+}
+
+// ValidateTemplate renders the template against a sample log and makes sure
+// the result is valid json
+func ValidateTemplate(template string) error {
+	if strings.TrimSpace(template) == "" {
+		return errors.New("your template cannot be empty")
+	}
+
+	sample := types.Log{
+		Message:    `sample "quoted" message`,
+		Level:      "info",
+		Timestamp:  time.Now(),
+		Attributes: map[string]string{"key": "value"},
+		Tags:       []string{"sample"},
+		Raw:        json.RawMessage(`{"message":"sample"}`),
+	}
+
+	if !json.Valid([]byte(Render(template, sample))) {
+		return errors.New("your template does not render to valid json")
+	}
+
+	return nil
+}
+
+func escape(value string) string {
+	quoted, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+
+	return string(quoted[1 : len(quoted)-1])
+}
@@ -0,0 +1,121 @@
+package forwarder
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ferretcode/pricetag/types"
+)
+
+func TestRender(t *testing.T) {
+	sample := types.Log{
+		Message:              `said "hi"` + "\n",
+		Level:                "warn",
+		Timestamp:            time.Date(2024, time.March, 10, 11, 59, 58, 500000000, time.UTC),
+		Tags:                 []string{"errors", "api"},
+		ServiceID:            "svc1",
+		ServiceName:          "api",
+		EnvironmentID:        "env1",
+		EnvironmentName:      "production",
+		ProjectID:            "p1",
+		ProjectName:          "shop",
+		DeploymentID:         "d1",
+		DeploymentInstanceID: "i1",
+		Attributes:           map[string]string{"status": "500"},
+		Raw:                  json.RawMessage(`{"message":"raw"}`),
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		log      types.Log
+		want     string
+	}{
+		{
+			name:     "escapes strings",
+			template: `{"text": "$LOG_CONTENT"}`,
+			log:      sample,
+			want:     `{"text": "said \"hi\"\n"}`,
+		},
+		{
+			name:     "longer names win over their prefixes",
+			template: `$LOG_DEPLOYMENT_INSTANCE_ID $LOG_DEPLOYMENT_ID $LOG_ENVIRONMENT_NAME $LOG_ENVIRONMENT_ID $LOG_PROJECT_NAME $LOG_PROJECT_ID $LOG_SERVICE_NAME $LOG_SERVICE_ID`,
+			log:      sample,
+			want:     `i1 d1 production env1 shop p1 api svc1`,
+		},
+		{
+			name:     "level, timestamp and tags",
+			template: `$LOG_LEVEL $LOG_TIMESTAMP $LOG_TAGS`,
+			log:      sample,
+			want:     `warn 2024-03-10T11:59:58.5Z errors,api`,
+		},
+		{
+			name:     "objects",
+			template: `{"attributes": $LOG_ATTRIBUTES, "raw": $LOG_JSON}`,
+			log:      sample,
+			want:     `{"attributes": {"status":"500"}, "raw": {"message":"raw"}}`,
+		},
+		{
+			name:     "missing objects are empty",
+			template: `{"attributes": $LOG_ATTRIBUTES, "raw": $LOG_JSON}`,
+			log:      types.Log{},
+			want:     `{"attributes": {}, "raw": {}}`,
+		},
+		{
+			name:     "unknown variables are left alone",
+			template: `$LOG_UNKNOWN`,
+			log:      sample,
+			want:     `$LOG_UNKNOWN`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Render(test.template, test.log); got != test.want {
+				t.Errorf("Render() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  bool
+	}{
+		{
+			name:     "valid",
+			template: `{"message": "$LOG_CONTENT", "attributes": $LOG_ATTRIBUTES}`,
+		},
+		{
+			name:     "whole log",
+			template: `$LOG_JSON`,
+		},
+		{
+			name:     "empty",
+			template: "  \n",
+			wantErr:  true,
+		},
+		{
+			name:     "unquoted string variable",
+			template: `{"message": $LOG_CONTENT}`,
+			wantErr:  true,
+		},
+		{
+			name:     "not json",
+			template: `message: $LOG_CONTENT`,
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateTemplate(test.template)
+			if (err != nil) != test.wantErr {
+				t.Errorf("ValidateTemplate() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package forwarder
+
+import (
+	"context"
+
+	"github.com/ferretcode/pricetag/types"
+)
+
+// sendWebhook posts each log rendered with the pipeline template
+func (f *Forwarder) sendWebhook(ctx context.Context, pipeline types.Pipeline, logs []types.Log) error {
+	for _, newLog := range logs {
+		err := f.post(ctx, pipeline.URL, []byte(Render(pipeline.Template, newLog)), nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
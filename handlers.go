@@ -4,28 +4,308 @@ import (
 	"net/http"
 
 	"github.com/ferretcode/pricetag/errors"
+	"github.com/ferretcode/pricetag/forwarder"
+	"github.com/ferretcode/pricetag/logstore"
+	"github.com/ferretcode/pricetag/matcher"
 	"github.com/ferretcode/pricetag/middleware"
 	"github.com/ferretcode/pricetag/routes/dashboard"
+	"github.com/ferretcode/pricetag/routes/forwarding"
+	"github.com/ferretcode/pricetag/routes/health"
+	"github.com/ferretcode/pricetag/routes/ingest"
+	"github.com/ferretcode/pricetag/routes/invites"
+	"github.com/ferretcode/pricetag/routes/logs"
+	"github.com/ferretcode/pricetag/routes/services"
+	"github.com/ferretcode/pricetag/routes/tags"
+	"github.com/ferretcode/pricetag/routes/tokens"
 	"github.com/ferretcode/pricetag/routes/user"
+	"github.com/ferretcode/pricetag/routes/users"
+	"github.com/ferretcode/pricetag/sink"
+	"github.com/ferretcode/pricetag/sources"
+	"github.com/ferretcode/pricetag/sources/railway"
+	"github.com/ferretcode/pricetag/stream"
+	"github.com/ferretcode/pricetag/types"
 	"github.com/go-chi/chi/v5"
 	"github.com/jmoiron/sqlx"
 )
 
-func registerHandlers(r chi.Router, db *sqlx.DB) {
+func registerHandlers(r chi.Router, db *sqlx.DB, logSink *sink.Sink, tagMatcher *matcher.Matcher, logForwarder *forwarder.Forwarder, logStore logstore.Store, liveHub *stream.Hub, activeSources []sources.Source, gql *railway.GraphQLConfig, railwayConfig *railway.Config) {
+	registerAPIHandlers(r, db, tagMatcher, logForwarder, logStore, gql, railwayConfig)
+
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		status, err := health.Health(w, r, activeSources)
+		if err != nil {
+			errors.HandleAPIError(w, "GET /health", status, err.Error())
+		}
+	})
+
+	r.Post("/ingest", func(w http.ResponseWriter, r *http.Request) {
+		status, err := ingest.Push(w, r, db, logSink)
+		if err != nil {
+			errors.HandleAPIError(w, "POST /ingest", status, err.Error())
+		}
+	})
+
 	r.Route("/dashboard", func(r chi.Router) {
 		r.Use(middleware.CheckUser(db, sessionManager, templates))
 
 		r.Get("/home", func(w http.ResponseWriter, r *http.Request) {
-			err := dashboard.Home(w, r, templates)
+			err := dashboard.Home(w, r, templates, activeSources)
 			if err != nil {
 				errors.HandleError(w, "/dashboard/home", http.StatusInternalServerError, err.Error(), templates)
 			}
 		})
+
+		r.Route("/users", func(r chi.Router) {
+			r.Use(middleware.RequirePermission(types.Permission.IsAdmin, templates))
+
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := users.RenderUsersPage(w, r, db, templates)
+				if err != nil {
+					errors.HandleError(w, "GET /dashboard/users", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/{id}/permissions", func(w http.ResponseWriter, r *http.Request) {
+				status, err := users.UpdatePermissions(w, r, db)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/users/{id}/permissions", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/{id}/password", func(w http.ResponseWriter, r *http.Request) {
+				status, err := users.ResetPassword(w, r, db, sessionManager)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/users/{id}/password", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/{id}/delete", func(w http.ResponseWriter, r *http.Request) {
+				status, err := users.Delete(w, r, db, sessionManager)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/users/{id}/delete", status, err.Error(), templates)
+				}
+			})
+		})
+
+		r.Route("/ingest", func(r chi.Router) {
+			r.Use(middleware.RequirePermission(types.Permission.CanManageServices, templates))
+
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := ingest.RenderIngestPage(w, r, db, templates)
+				if err != nil {
+					errors.HandleError(w, "GET /dashboard/ingest", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := ingest.Create(w, r, db, templates)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/ingest", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/{id}/delete", func(w http.ResponseWriter, r *http.Request) {
+				status, err := ingest.Delete(w, r, db)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/ingest/{id}/delete", status, err.Error(), templates)
+				}
+			})
+		})
+
+		r.Route("/tokens", func(r chi.Router) {
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := tokens.RenderTokensPage(w, r, db, templates)
+				if err != nil {
+					errors.HandleError(w, "GET /dashboard/tokens", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := tokens.Create(w, r, db, templates)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/tokens", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/{id}/delete", func(w http.ResponseWriter, r *http.Request) {
+				status, err := tokens.Delete(w, r, db)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/tokens/{id}/delete", status, err.Error(), templates)
+				}
+			})
+		})
+
+		r.Route("/invites", func(r chi.Router) {
+			r.Use(middleware.RequirePermission(types.Permission.IsAdmin, templates))
+
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := invites.RenderInvitesPage(w, r, db, templates)
+				if err != nil {
+					errors.HandleError(w, "GET /dashboard/invites", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := invites.Create(w, r, db, templates)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/invites", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/{id}/delete", func(w http.ResponseWriter, r *http.Request) {
+				status, err := invites.Delete(w, r, db)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/invites/{id}/delete", status, err.Error(), templates)
+				}
+			})
+		})
+
+		r.Route("/logs", func(r chi.Router) {
+			r.Use(middleware.RequirePermission(types.Permission.CanViewLogs, templates))
+
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := logs.RenderLivePage(w, r, tagMatcher, templates)
+				if err != nil {
+					errors.HandleError(w, "GET /dashboard/logs", status, err.Error(), templates)
+				}
+			})
+
+			r.Get("/stream", func(w http.ResponseWriter, r *http.Request) {
+				status, err := logs.Stream(w, r, liveHub)
+				if err != nil {
+					errors.HandleError(w, "GET /dashboard/logs/stream", status, err.Error(), templates)
+				}
+			})
+
+			r.Get("/history", func(w http.ResponseWriter, r *http.Request) {
+				status, err := logs.RenderHistoryPage(w, r, logStore, tagMatcher, templates)
+				if err != nil {
+					errors.HandleError(w, "GET /dashboard/logs/history", status, err.Error(), templates)
+				}
+			})
+		})
+
+		r.Route("/services", func(r chi.Router) {
+			r.Use(middleware.RequirePermission(types.Permission.CanManageServices, templates))
+
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := services.RenderServicesPage(w, r, db, gql, railwayConfig, templates)
+				if err != nil {
+					errors.HandleError(w, "GET /dashboard/services", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/track", func(w http.ResponseWriter, r *http.Request) {
+				status, err := services.Track(w, r, db, gql, railwayConfig)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/services/track", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/{serviceID}/untrack", func(w http.ResponseWriter, r *http.Request) {
+				status, err := services.Untrack(w, r, db, railwayConfig)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/services/{serviceID}/untrack", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/environments", func(w http.ResponseWriter, r *http.Request) {
+				status, err := services.AddEnvironment(w, r, db, gql, railwayConfig)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/services/environments", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/environments/{environmentID}/remove", func(w http.ResponseWriter, r *http.Request) {
+				status, err := services.RemoveEnvironment(w, r, db, railwayConfig)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/services/environments/{environmentID}/remove", status, err.Error(), templates)
+				}
+			})
+		})
+
+		r.Route("/tags", func(r chi.Router) {
+			r.Use(middleware.RequirePermission(types.Permission.CanManageTags, templates))
+
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := tags.RenderTagsPage(w, r, tagMatcher, templates)
+				if err != nil {
+					errors.HandleError(w, "GET /dashboard/tags", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/create", func(w http.ResponseWriter, r *http.Request) {
+				status, err := tags.Create(w, r, db, tagMatcher)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/tags/create", status, err.Error(), templates)
+				}
+			})
+
+			r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+				status, err := tags.RenderEditTagPage(w, r, db, templates)
+				if err != nil {
+					errors.HandleError(w, "GET /dashboard/tags/{id}", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/{id}/edit", func(w http.ResponseWriter, r *http.Request) {
+				status, err := tags.Update(w, r, db, tagMatcher)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/tags/{id}/edit", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/{id}/delete", func(w http.ResponseWriter, r *http.Request) {
+				status, err := tags.Delete(w, r, db, tagMatcher)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/tags/{id}/delete", status, err.Error(), templates)
+				}
+			})
+		})
+
+		r.Route("/forwarding", func(r chi.Router) {
+			r.Use(middleware.RequirePermission(types.Permission.CanManageForwarding, templates))
+
+			r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+				status, err := forwarding.RenderForwardingPage(w, r, logForwarder, tagMatcher, templates)
+				if err != nil {
+					errors.HandleError(w, "GET /dashboard/forwarding", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/create", func(w http.ResponseWriter, r *http.Request) {
+				status, err := forwarding.Create(w, r, db, logForwarder)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/forwarding/create", status, err.Error(), templates)
+				}
+			})
+
+			r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+				status, err := forwarding.RenderEditPipelinePage(w, r, db, tagMatcher, templates)
+				if err != nil {
+					errors.HandleError(w, "GET /dashboard/forwarding/{id}", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/{id}/edit", func(w http.ResponseWriter, r *http.Request) {
+				status, err := forwarding.Update(w, r, db, logForwarder)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/forwarding/{id}/edit", status, err.Error(), templates)
+				}
+			})
+
+			r.Post("/{id}/delete", func(w http.ResponseWriter, r *http.Request) {
+				status, err := forwarding.Delete(w, r, db, logForwarder)
+				if err != nil {
+					errors.HandleError(w, "POST /dashboard/forwarding/{id}/delete", status, err.Error(), templates)
+				}
+			})
+		})
 	})
 
 	r.Route("/user", func(r chi.Router) {
 		r.Get("/create", func(w http.ResponseWriter, r *http.Request) {
-			err := user.RenderCreateUserPage(w, r, templates)
+			err := user.RenderCreateUserPage(w, r, db, templates)
 			if err != nil {
 				errors.HandleError(w, "GET /user/create", http.StatusInternalServerError, err.Error(), templates)
 			}
@@ -51,5 +331,12 @@ func registerHandlers(r chi.Router, db *sqlx.DB) {
 				errors.HandleError(w, "POST /user/login", status, err.Error(), templates)
 			}
 		})
+
+		r.Post("/logout", func(w http.ResponseWriter, r *http.Request) {
+			status, err := user.Logout(w, r, sessionManager)
+			if err != nil {
+				errors.HandleError(w, "POST /user/logout", status, err.Error(), templates)
+			}
+		})
 	})
 }
@@ -0,0 +1,285 @@
+package logstore
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+type SQLiteStore struct {
+	db *sqlx.DB
+}
+
+type logRow struct {
+	ID                   int64  `db:"ID"`
+	Timestamp            int64  `db:"Timestamp"`
+	Level                string `db:"Level"`
+	Message              string `db:"Message"`
+	ServiceID            string `db:"ServiceID"`
+	ServiceName          string `db:"ServiceName"`
+	EnvironmentID        string `db:"EnvironmentID"`
+	EnvironmentName      string `db:"EnvironmentName"`
+	ProjectID            string `db:"ProjectID"`
+	ProjectName          string `db:"ProjectName"`
+	DeploymentID         string `db:"DeploymentID"`
+	DeploymentInstanceID string `db:"DeploymentInstanceID"`
+	Attributes           string `db:"Attributes"`
+	Raw                  string `db:"Raw"`
+}
+
+type logTagRow struct {
+	LogID int64  `db:"LogID"`
+	Tag   string `db:"Tag"`
+}
+
+func NewSQLiteStore(db *sqlx.DB) *SQLiteStore {
+	return &SQLiteStore{
+		db: db,
+	}
+}
+
+func (s *SQLiteStore) Write(ctx context.Context, logs []types.Log) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := range logs {
+		attributes, err := json.Marshal(logs[i].Attributes)
+		if err != nil {
+			return err
+		}
+
+		raw := string(logs[i].Raw)
+		if raw == "" {
+			raw = "{}"
+		}
+
+		timestamp := logs[i].Timestamp.UnixNano()
+
+		insertLogQuery := squirrel.
+			Insert("Log").
+			Columns(
+				"Timestamp", "Level", "Message",
+				"ServiceID", "ServiceName",
+				"EnvironmentID", "EnvironmentName",
+				"ProjectID", "ProjectName",
+				"DeploymentID", "DeploymentInstanceID",
+				"Attributes", "Raw",
+			).
+			Values(
+				timestamp, logs[i].Level, logs[i].Message,
+				logs[i].ServiceID, logs[i].ServiceName,
+				logs[i].EnvironmentID, logs[i].EnvironmentName,
+				logs[i].ProjectID, logs[i].ProjectName,
+				logs[i].DeploymentID, logs[i].DeploymentInstanceID,
+				string(attributes), raw,
+			)
+
+		sql, args, err := insertLogQuery.ToSql()
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, sql, args...)
+		if err != nil {
+			return err
+		}
+
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		for _, tag := range logs[i].Tags {
+			insertLogTagQuery := squirrel.
+				Insert("LogTag").
+				Columns("LogID", "Tag", "Timestamp").
+				Values(id, tag, timestamp)
+
+			sql, args, err := insertLogTagQuery.ToSql()
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx, sql, args...)
+			if err != nil {
+				return err
+			}
+		}
+
+		logs[i].ID = id
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) Query(ctx context.Context, query Query) (Page, error) {
+	limit := query.limit()
+
+	selectLogsQuery := squirrel.
+		Select("Log.*").
+		From("Log").
+		OrderBy("Log.Timestamp DESC", "Log.ID DESC").
+		// fetch one extra row to know if there is another page
+		Limit(uint64(limit + 1))
+
+	if query.Tag != "" {
+		selectLogsQuery = selectLogsQuery.
+			Join("LogTag ON LogTag.LogID = Log.ID").
+			Where(squirrel.Eq{"LogTag.Tag": query.Tag})
+	}
+
+	if !query.From.IsZero() {
+		selectLogsQuery = selectLogsQuery.Where(squirrel.GtOrEq{"Log.Timestamp": query.From.UnixNano()})
+	}
+
+	if !query.To.IsZero() {
+		selectLogsQuery = selectLogsQuery.Where(squirrel.Lt{"Log.Timestamp": query.To.UnixNano()})
+	}
+
+	if query.Level != "" {
+		selectLogsQuery = selectLogsQuery.Where(squirrel.Eq{"Log.Level": query.Level})
+	}
+
+	if query.ServiceID != "" {
+		selectLogsQuery = selectLogsQuery.Where(squirrel.Eq{"Log.ServiceID": query.ServiceID})
+	}
+
+	if query.Keyword != "" {
+		selectLogsQuery = selectLogsQuery.Where("Log.Message LIKE ? ESCAPE '\\'", "%"+escapeLike(query.Keyword)+"%")
+	}
+
+	if query.Cursor != "" {
+		after, err := parseCursor(query.Cursor)
+		if err != nil {
+			return Page{}, err
+		}
+
+		selectLogsQuery = selectLogsQuery.Where(
+			"(Log.Timestamp < ? OR (Log.Timestamp = ? AND Log.ID < ?))",
+			after.Timestamp, after.Timestamp, after.ID,
+		)
+	}
+
+	sql, args, err := selectLogsQuery.ToSql()
+	if err != nil {
+		return Page{}, err
+	}
+
+	rows := []logRow{}
+
+	err = s.db.SelectContext(ctx, &rows, sql, args...)
+	if err != nil {
+		return Page{}, err
+	}
+
+	page := Page{}
+
+	if len(rows) > limit {
+		rows = rows[:limit]
+
+		last := rows[len(rows)-1]
+		page.Next = cursor{Timestamp: last.Timestamp, ID: last.ID}.String()
+	}
+
+	tags, err := s.getTags(ctx, rows)
+	if err != nil {
+		return Page{}, err
+	}
+
+	page.Logs = make([]types.Log, len(rows))
+
+	for i := range rows {
+		page.Logs[i], err = rows[i].toLog()
+		if err != nil {
+			return Page{}, err
+		}
+
+		page.Logs[i].Tags = tags[rows[i].ID]
+	}
+
+	return page, nil
+}
+
+func (s *SQLiteStore) getTags(ctx context.Context, rows []logRow) (map[int64][]string, error) {
+	tags := map[int64][]string{}
+
+	if len(rows) == 0 {
+		return tags, nil
+	}
+
+	ids := make([]int64, len(rows))
+	for i := range rows {
+		ids[i] = rows[i].ID
+	}
+
+	selectTagsQuery := squirrel.
+		Select("LogID", "Tag").
+		From("LogTag").
+		Where(squirrel.Eq{"LogID": ids})
+
+	sql, args, err := selectTagsQuery.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	tagRows := []logTagRow{}
+
+	err = s.db.SelectContext(ctx, &tagRows, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tagRow := range tagRows {
+		tags[tagRow.LogID] = append(tags[tagRow.LogID], tagRow.Tag)
+	}
+
+	return tags, nil
+}
+
+func (r logRow) toLog() (types.Log, error) {
+	attributes := map[string]string{}
+
+	err := json.Unmarshal([]byte(r.Attributes), &attributes)
+	if err != nil {
+		return types.Log{}, err
+	}
+
+	return types.Log{
+		ID:         r.ID,
+		Message:    r.Message,
+		Level:      r.Level,
+		Timestamp:  time.Unix(0, r.Timestamp).UTC(),
+		Attributes: attributes,
+
+		ServiceID:            r.ServiceID,
+		ServiceName:          r.ServiceName,
+		EnvironmentID:        r.EnvironmentID,
+		EnvironmentName:      r.EnvironmentName,
+		ProjectID:            r.ProjectID,
+		ProjectName:          r.ProjectName,
+		DeploymentID:         r.DeploymentID,
+		DeploymentInstanceID: r.DeploymentInstanceID,
+
+		Raw: json.RawMessage(r.Raw),
+	}, nil
+}
+
+func escapeLike(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `%`, `\%`)
+	value = strings.ReplaceAll(value, `_`, `\_`)
+
+	return value
+}
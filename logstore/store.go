@@ -0,0 +1,83 @@
+package logstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ferretcode/pricetag/types"
+)
+
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// Store persists logs so they can be queried after they have left the sink
+type Store interface {
+	Write(ctx context.Context, logs []types.Log) error
+	Query(ctx context.Context, query Query) (Page, error)
+}
+
+// Query selects logs inside [From, To), newest first. Empty fields are not
+// filtered on
+type Query struct {
+	From      time.Time
+	To        time.Time
+	Level     string
+	ServiceID string
+	Tag       string
+	Keyword   string
+	// Cursor is the Next value of the previous page
+	Cursor string
+	Limit  int
+}
+
+type Page struct {
+	Logs []types.Log `json:"logs"`
+	// Next is empty when there are no older logs in the window
+	Next string `json:"next"`
+}
+
+type cursor struct {
+	Timestamp int64
+	ID        int64
+}
+
+func (c cursor) String() string {
+	return fmt.Sprintf("%d-%d", c.Timestamp, c.ID)
+}
+
+func parseCursor(value string) (cursor, error) {
+	timestamp, id, ok := strings.Cut(value, "-")
+	if !ok {
+		return cursor{}, errors.New("invalid cursor")
+	}
+
+	parsedTimestamp, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return cursor{}, errors.New("invalid cursor")
+	}
+
+	parsedID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return cursor{}, errors.New("invalid cursor")
+	}
+
+	return cursor{Timestamp: parsedTimestamp, ID: parsedID}, nil
+}
+
+func (q Query) limit() int {
+	if q.Limit <= 0 {
+		return DefaultLimit
+	}
+
+	if q.Limit > MaxLimit {
+		return MaxLimit
+	}
+
+	return q.Limit
+}
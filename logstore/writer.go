@@ -0,0 +1,95 @@
+package logstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/types"
+)
+
+const (
+	queueSize     = 1000
+	batchSize     = 500
+	flushInterval = time.Second
+)
+
+// Writer batches logs from the sink into a Store
+type Writer struct {
+	store Store
+	queue chan []types.Log
+}
+
+func NewWriter(store Store) *Writer {
+	return &Writer{
+		store: store,
+		queue: make(chan []types.Log, queueSize),
+	}
+}
+
+// Handle is a sink.Handler. Batches are dropped rather than blocking the sink
+// when the store falls behind
+func (w *Writer) Handle(logs []types.Log) {
+	select {
+	case w.queue <- logs:
+	default:
+		log.Warn("log store queue is full, dropping logs", "count", len(logs))
+	}
+}
+
+func (w *Writer) Run(ctx context.Context) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := []types.Log{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			batch = w.drain(batch)
+
+			// the run context is gone, give the final flush its own deadline
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			w.flush(flushCtx, batch)
+			cancel()
+
+			return
+		case logs := <-w.queue:
+			batch = append(batch, logs...)
+
+			if len(batch) >= batchSize {
+				w.flush(ctx, batch)
+				batch = []types.Log{}
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.flush(ctx, batch)
+				batch = []types.Log{}
+			}
+		}
+	}
+}
+
+// drain adds what is still queued to batch without waiting for more, so
+// logs handled just before shutdown make it into the final flush
+func (w *Writer) drain(batch []types.Log) []types.Log {
+	for {
+		select {
+		case logs := <-w.queue:
+			batch = append(batch, logs...)
+		default:
+			return batch
+		}
+	}
+}
+
+func (w *Writer) flush(ctx context.Context, batch []types.Log) {
+	if len(batch) == 0 {
+		return
+	}
+
+	err := w.store.Write(ctx, batch)
+	if err != nil {
+		log.Error("error writing logs to the store", "count", len(batch), "err", err)
+	}
+}
@@ -1,15 +1,24 @@
 package main
 
 import (
+	"context"
 	"html/template"
+	"net"
 	"net/http"
 	"os"
-	"strings"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/charmbracelet/log"
 	database "github.com/ferretcode/pricetag/db"
+	"github.com/ferretcode/pricetag/forwarder"
+	"github.com/ferretcode/pricetag/logstore"
+	"github.com/ferretcode/pricetag/matcher"
 	"github.com/ferretcode/pricetag/session"
+	"github.com/ferretcode/pricetag/sink"
+	"github.com/ferretcode/pricetag/stream"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jmoiron/sqlx"
@@ -19,17 +28,30 @@ import (
 )
 
 var templates *template.Template
-var sessionManager = session.NewSessionManager(30 * time.Minute)
+var sessionManager *session.SessionManager
 
 func parseTemplates() error {
 	var err error
 
 	files := []string{
 		"./views/fragments/navbar.html",
+		"./views/fragments/tag_form.html",
+		"./views/fragments/pipeline_form.html",
 		"./views/home.html",
 		"./views/error.html",
 		"./views/user/create.html",
 		"./views/user/login.html",
+		"./views/tags/tags.html",
+		"./views/tags/edit_tag.html",
+		"./views/services/services.html",
+		"./views/forwarding/forwarding.html",
+		"./views/forwarding/edit_pipeline.html",
+		"./views/logs/history.html",
+		"./views/logs/live.html",
+		"./views/users/users.html",
+		"./views/invites/invites.html",
+		"./views/tokens/tokens.html",
+		"./views/ingest/ingest.html",
 	}
 
 	templates, err = template.ParseFiles(files...)
@@ -58,13 +80,28 @@ func main() {
 	db.SetMaxOpenConns(1)
 	db.SetMaxIdleConns(1)
 
-	err = database.RunMigrations(db)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = runMigrateCommand(db, os.Args[2:])
+		if err != nil {
+			log.Error("error running migrate command", "err", err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	_, err = database.MigrateUp(db)
 	if err != nil {
 		log.Error("error running database migrations", "err", err)
+		os.Exit(1)
+	}
 
-		if !strings.Contains(err.Error(), "already exists") {
-			os.Exit(1)
-		}
+	sessionManager = session.NewSessionManager(newSessionStore(db), 30*time.Minute)
+
+	tagMatcher, err := matcher.NewMatcher(db)
+	if err != nil {
+		log.Error("error loading tags", "err", err)
+		os.Exit(1)
 	}
 
 	err = parseTemplates()
@@ -73,16 +110,97 @@ func main() {
 		os.Exit(1)
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	wg := &sync.WaitGroup{}
+
+	logForwarder, err := forwarder.NewForwarder(db, tagMatcher)
+	if err != nil {
+		log.Error("error loading forwarding pipelines", "err", err)
+		os.Exit(1)
+	}
+
+	logStore := logstore.NewSQLiteStore(db)
+	logWriter := logstore.NewWriter(logStore)
+
+	liveHub := stream.NewHub()
+
+	logSink := sink.NewSink(100)
+	logSink.Handle(tagMatcher.Apply)
+	logSink.Handle(logWriter.Handle)
+	logSink.Handle(logForwarder.Handle)
+	logSink.Handle(liveHub.Handle)
+
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		logSink.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		logWriter.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		logForwarder.Run(ctx)
+	}()
+
+	activeSources, err := buildSources(db, tagMatcher)
+	if err != nil {
+		log.Error("error building log sources", "err", err)
+		os.Exit(1)
+	}
+
+	startSources(ctx, wg, activeSources, logSink)
+
+	gql, railwayConfig := findRailway(activeSources)
+
 	r := chi.NewRouter()
 
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Logger)
 
-	registerHandlers(r, db)
+	registerHandlers(r, db, logSink, tagMatcher, logForwarder, logStore, liveHub, activeSources, gql, railwayConfig)
+
+	server := &http.Server{
+		// TODO: change in production
+		// TODO: implement TLS
+		// Addr: ":" + os.Getenv("PORT"),
+		Addr:    "localhost:" + os.Getenv("PORT"),
+		Handler: r,
+		// cancels long lived requests such as the live log stream on shutdown
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Error("error shutting down http server", "err", err)
+		}
+	}()
+
+	err = server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		log.Error("error serving http", "err", err)
+		stop()
+	}
+
+	wg.Wait()
+
+	log.Info("shut down cleanly")
+}
+
+// newSessionStore keeps sessions in the db unless SESSION_STORE=memory
+func newSessionStore(db *sqlx.DB) session.SessionStore {
+	if os.Getenv("SESSION_STORE") == "memory" {
+		return session.NewMemoryStore()
+	}
 
-	// TODO: change in production
-	// TODO: implement TLS
-	// http.ListenAndServe(":"+os.Getenv("PORT"), r)
-	http.ListenAndServe("localhost:"+os.Getenv("PORT"), r)
+	return session.NewSQLiteStore(db)
 }
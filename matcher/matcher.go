@@ -0,0 +1,139 @@
+package matcher
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+// Matcher keeps an in-memory copy of the Tag table so every ingested log
+// can be checked without a round trip to the db
+type Matcher struct {
+	db   *sqlx.DB
+	mu   sync.RWMutex
+	tags []types.Tag
+	// changed is closed by the next Reload, so any number of watchers can
+	// wait for it
+	changed chan struct{}
+}
+
+func NewMatcher(db *sqlx.DB) (*Matcher, error) {
+	matcher := &Matcher{
+		db:      db,
+		changed: make(chan struct{}),
+	}
+
+	if err := matcher.Reload(); err != nil {
+		return nil, err
+	}
+
+	return matcher, nil
+}
+
+// Reload must be called after the Tag table is modified
+func (m *Matcher) Reload() error {
+	selectTagsQuery := squirrel.
+		Select("*").
+		From("Tag").
+		OrderBy("Name")
+
+	sql, args, err := selectTagsQuery.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tags := []types.Tag{}
+
+	err = m.db.Select(&tags, sql, args...)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.tags = tags
+	close(m.changed)
+	m.changed = make(chan struct{})
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Changed is closed the next time the tags are reloaded
+func (m *Matcher) Changed() <-chan struct{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.changed
+}
+
+func (m *Matcher) Tags() []types.Tag {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tags := make([]types.Tag, len(m.tags))
+	copy(tags, m.tags)
+
+	return tags
+}
+
+// Match returns every tag the log satisfies
+func (m *Matcher) Match(log types.Log) []types.Tag {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matched := []types.Tag{}
+
+	for _, tag := range m.tags {
+		if MatchTag(tag, log) {
+			matched = append(matched, tag)
+		}
+	}
+
+	return matched
+}
+
+// Apply sets the Tags field of each log to the names of the tags it matches
+func (m *Matcher) Apply(logs []types.Log) {
+	for i := range logs {
+		matched := m.Match(logs[i])
+
+		names := make([]string, len(matched))
+		for j := range matched {
+			names[j] = matched[j].Name
+		}
+
+		logs[i].Tags = names
+	}
+}
+
+// MatchTag reports whether the log satisfies every criterion set on the tag.
+// A tag with no criteria never matches
+func MatchTag(tag types.Tag, log types.Log) bool {
+	if tag.Keyword == "" && tag.AttributeKey == "" && tag.ServiceID == "" {
+		return false
+	}
+
+	if tag.ServiceID != "" && tag.ServiceID != log.ServiceID {
+		return false
+	}
+
+	if tag.Keyword != "" && !strings.Contains(strings.ToLower(log.Message), strings.ToLower(tag.Keyword)) {
+		return false
+	}
+
+	if tag.AttributeKey != "" {
+		value, ok := log.Attributes[tag.AttributeKey]
+		if !ok {
+			return false
+		}
+
+		if tag.AttributeValue != "" && value != tag.AttributeValue {
+			return false
+		}
+	}
+
+	return true
+}
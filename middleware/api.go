@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/ferretcode/pricetag/errors"
+	"github.com/ferretcode/pricetag/session"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+// CheckAPIUser is CheckUser for /api routes, failures are reported as json
+// and a missing or expired session is a 401 rather than a redirect to log in.
+//
+// Requests carrying an "Authorization: Bearer" api token are authenticated by
+// the token alone, the session cookie is only used without the header
+func CheckAPIUser(db *sqlx.DB, session *session.SessionManager) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if header := r.Header.Get("Authorization"); header != "" {
+				apiToken, ok := bearerToken(header)
+				if !ok {
+					errors.HandleAPIError(w, r.URL.Path, 401, "the authorization header must be a bearer token")
+					return
+				}
+
+				user, permission, status, err := getTokenUserAndPermission(db, apiToken)
+				if err != nil {
+					errors.HandleAPIError(w, r.URL.Path, status, err.Error())
+					return
+				}
+
+				next.ServeHTTP(w, r.WithContext(withUser(r.Context(), user, permission)))
+				return
+			}
+
+			cookie, err := r.Cookie("session_id")
+			if err != nil {
+				errors.HandleAPIError(w, r.URL.Path, 401, "authentication required")
+				return
+			}
+
+			session, err := session.GetSession(cookie.Value)
+			if err != nil {
+				errors.HandleAPIError(w, r.URL.Path, 401, err.Error())
+				return
+			}
+
+			user, permission, status, err := getUserAndPermission(db, session.UserID)
+			if err != nil {
+				errors.HandleAPIError(w, r.URL.Path, status, err.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(withUser(r.Context(), user, permission)))
+		})
+	}
+}
+
+// RequireAPIPermission is RequirePermission for /api routes
+func RequireAPIPermission(allowed func(types.Permission) bool) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			permission, ok := r.Context().Value("permission").(types.Permission)
+			if !ok || !allowed(permission) {
+				errors.HandleAPIError(w, r.URL.Path, 403, errForbidden.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
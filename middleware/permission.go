@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	goerrors "errors"
+	"html/template"
+	"net/http"
+
+	"github.com/ferretcode/pricetag/errors"
+	"github.com/ferretcode/pricetag/types"
+)
+
+var errForbidden = goerrors.New("you may not access this resource")
+
+// RequirePermission must be used after CheckUser. It rejects users whose
+// permission does not satisfy allowed, e.g. types.Permission.CanManageTags
+func RequirePermission(allowed func(types.Permission) bool, templates *template.Template) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			permission, ok := r.Context().Value("permission").(types.Permission)
+			if !ok || !allowed(permission) {
+				errors.HandleError(w, r.URL.Path, 403, errForbidden.Error(), templates)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"database/sql"
+	goerrors "errors"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ferretcode/pricetag/token"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+// tokenTouchInterval limits how often LastUsedAt is written for a token that
+// is used in a tight loop
+const tokenTouchInterval = time.Minute
+
+var errInvalidToken = goerrors.New("invalid or expired api token")
+
+// bearerToken returns the token of an "Authorization: Bearer" header
+func bearerToken(header string) (string, bool) {
+	scheme, value, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	value = strings.TrimSpace(value)
+
+	return value, value != ""
+}
+
+// getTokenUserAndPermission resolves an api token to its user. The returned
+// permission is the user's permission narrowed to the token's scope
+func getTokenUserAndPermission(db *sqlx.DB, apiToken string) (user types.User, permission types.Permission, status int, err error) {
+	selectTokenQuery := squirrel.
+		Select("*").
+		From("APIToken").
+		Where(squirrel.Eq{"TokenHash": token.Hash(apiToken)})
+
+	query, args, err := selectTokenQuery.ToSql()
+	if err != nil {
+		return types.User{}, types.Permission{}, 500, err
+	}
+
+	storedToken := types.APIToken{}
+
+	err = db.Get(&storedToken, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.User{}, types.Permission{}, 401, errInvalidToken
+		}
+		return types.User{}, types.Permission{}, 500, err
+	}
+
+	if storedToken.Expired() {
+		return types.User{}, types.Permission{}, 401, errInvalidToken
+	}
+
+	user, permission, status, err = getUserAndPermission(db, storedToken.UserID)
+	if err != nil {
+		return types.User{}, types.Permission{}, status, err
+	}
+
+	now := time.Now()
+
+	if now.Unix()-storedToken.LastUsedAt >= int64(tokenTouchInterval.Seconds()) {
+		touchTokenQuery := squirrel.
+			Update("APIToken").
+			Set("LastUsedAt", now.Unix()).
+			Where(squirrel.Eq{"ID": storedToken.ID})
+
+		query, args, err = touchTokenQuery.ToSql()
+		if err != nil {
+			return types.User{}, types.Permission{}, 500, err
+		}
+
+		_, err = db.Exec(query, args...)
+		if err != nil {
+			return types.User{}, types.Permission{}, 500, err
+		}
+	}
+
+	return user, storedToken.Scope(permission), 200, nil
+}
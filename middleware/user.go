@@ -16,12 +16,9 @@ func CheckUser(db *sqlx.DB, session *session.SessionManager, templates *template
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			cookie, err := r.Cookie("session_id")
-
 			if err != nil {
-				if err == http.ErrNoCookie {
-					errors.HandleError(w, r.URL.Path, 403, "please log in", templates)
-					return
-				}
+				errors.HandleError(w, r.URL.Path, 403, "please log in", templates)
+				return
 			}
 
 			session, err := session.GetSession(cookie.Value)
@@ -30,47 +27,54 @@ func CheckUser(db *sqlx.DB, session *session.SessionManager, templates *template
 				return
 			}
 
-			user := types.User{}
-			permission := types.Permission{}
-
-			selectUserQuery := squirrel.
-				Select("*").
-				From("User").
-				Where(squirrel.Eq{"ID": session.UserID})
-
-			sql, args, err := selectUserQuery.ToSql()
+			user, permission, status, err := getUserAndPermission(db, session.UserID)
 			if err != nil {
-				errors.HandleError(w, r.URL.Path, 500, err.Error(), templates)
+				errors.HandleError(w, r.URL.Path, status, err.Error(), templates)
 				return
 			}
 
-			err = db.Get(&user, sql, args...)
-			if err != nil {
-				errors.HandleError(w, r.URL.Path, 403, "you may not access this resource", templates)
-				return
-			}
+			next.ServeHTTP(w, r.WithContext(withUser(r.Context(), user, permission)))
+		})
+	}
+}
 
-			selectPermissionQuery := squirrel.
-				Select("*").
-				From("Permission").
-				Where(squirrel.Eq{"UserID": session.UserID})
+func withUser(ctx context.Context, user types.User, permission types.Permission) context.Context {
+	ctx = context.WithValue(ctx, "user", user)
+	ctx = context.WithValue(ctx, "permission", permission)
 
-			sql, args, err = selectPermissionQuery.ToSql()
-			if err != nil {
-				errors.HandleError(w, r.URL.Path, 500, err.Error(), templates)
-				return
-			}
+	return ctx
+}
 
-			err = db.Get(&permission, sql, args...)
-			if err != nil {
-				errors.HandleError(w, r.URL.Path, 403, "you may not access this resource", templates)
-				return
-			}
+func getUserAndPermission(db *sqlx.DB, userID int) (user types.User, permission types.Permission, status int, err error) {
+	selectUserQuery := squirrel.
+		Select("*").
+		From("User").
+		Where(squirrel.Eq{"ID": userID})
 
-			ctx := context.WithValue(r.Context(), "user", user)
-			ctx = context.WithValue(ctx, "permission", permission)
+	sql, args, err := selectUserQuery.ToSql()
+	if err != nil {
+		return types.User{}, types.Permission{}, 500, err
+	}
 
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
+	err = db.Get(&user, sql, args...)
+	if err != nil {
+		return types.User{}, types.Permission{}, 403, errForbidden
 	}
+
+	selectPermissionQuery := squirrel.
+		Select("*").
+		From("Permission").
+		Where(squirrel.Eq{"UserID": userID})
+
+	sql, args, err = selectPermissionQuery.ToSql()
+	if err != nil {
+		return types.User{}, types.Permission{}, 500, err
+	}
+
+	err = db.Get(&permission, sql, args...)
+	if err != nil {
+		return types.User{}, types.Permission{}, 403, errForbidden
+	}
+
+	return user, permission, 200, nil
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	database "github.com/ferretcode/pricetag/db"
+	"github.com/jmoiron/sqlx"
+)
+
+const migrateUsage = "usage: pricetag migrate up | down [steps] | status"
+
+// runMigrateCommand handles `pricetag migrate <subcommand>`
+func runMigrateCommand(db *sqlx.DB, args []string) error {
+	if len(args) < 1 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(db)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("applied %d migration(s)\n", len(applied))
+	case "down":
+		steps := 1
+
+		if len(args) > 1 {
+			var err error
+
+			steps, err = strconv.Atoi(args[1])
+			if err != nil {
+				return errors.New(migrateUsage)
+			}
+		}
+
+		reverted, err := database.MigrateDown(db, steps)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("reverted %d migration(s)\n", len(reverted))
+	case "status":
+		status, err := database.GetMigrationStatus(db)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+
+		for _, s := range status {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Migration.Version, s.Migration.Name, appliedAt)
+		}
+
+		return w.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
@@ -4,18 +4,21 @@ import (
 	"html/template"
 	"net/http"
 
+	"github.com/ferretcode/pricetag/sources"
 	"github.com/ferretcode/pricetag/types"
 )
 
 type homeData struct {
 	User       types.User
 	Permission types.Permission
+	Sources    []sources.Status
 }
 
-func Home(w http.ResponseWriter, r *http.Request, templates *template.Template) error {
+func Home(w http.ResponseWriter, r *http.Request, templates *template.Template, activeSources []sources.Source) error {
 	err := templates.ExecuteTemplate(w, "home.html", homeData{
 		User:       r.Context().Value("user").(types.User),
 		Permission: r.Context().Value("permission").(types.Permission),
+		Sources:    sources.Statuses(activeSources),
 	})
 	if err != nil {
 		return err
@@ -0,0 +1,128 @@
+package forwarding
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/ferretcode/pricetag/api"
+	"github.com/ferretcode/pricetag/forwarder"
+	"github.com/jmoiron/sqlx"
+)
+
+func APIList(w http.ResponseWriter, r *http.Request, logForwarder *forwarder.Forwarder) (status int, err error) {
+	err = api.WriteJSON(w, 200, logForwarder.Pipelines())
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
+
+func APIGet(w http.ResponseWriter, r *http.Request, db *sqlx.DB) (status int, err error) {
+	id, err := getPipelineID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	return writePipeline(w, 200, id, db)
+}
+
+// APICreate creates an enabled webhook pipeline unless the body sets kind
+// or enabled. Elasticsearch pipelines get the default index pattern and chat
+// pipelines the default rate limit
+func APICreate(w http.ResponseWriter, r *http.Request, db *sqlx.DB, logForwarder *forwarder.Forwarder) (status int, err error) {
+	pipelineRequest := pipelineRequest{
+		Kind:         forwarder.KindWebhook,
+		IndexPattern: forwarder.DefaultIndexPattern,
+		RateLimit:    forwarder.DefaultRateLimit,
+		Enabled:      true,
+	}
+
+	err = api.DecodeJSON(r, &pipelineRequest)
+	if err != nil {
+		return 400, err
+	}
+
+	id, status, err := createPipeline(pipelineRequest, db, logForwarder)
+	if err != nil {
+		return status, err
+	}
+
+	return writePipeline(w, 201, id, db)
+}
+
+// APIUpdate changes the fields present in the body, the others keep their
+// current value
+func APIUpdate(w http.ResponseWriter, r *http.Request, db *sqlx.DB, logForwarder *forwarder.Forwarder) (status int, err error) {
+	id, err := getPipelineID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	pipeline, err := getPipeline(id, db)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 404, errPipelineNotFound
+		}
+		return 500, err
+	}
+
+	pipelineRequest := pipelineRequest{
+		Name:         pipeline.Name,
+		Kind:         pipeline.Kind,
+		URL:          pipeline.URL,
+		Template:     pipeline.Template,
+		IndexPattern: pipeline.IndexPattern,
+		RateLimit:    pipeline.RateLimit,
+		TagID:        pipeline.TagID,
+		Enabled:      pipeline.Enabled,
+	}
+
+	err = api.DecodeJSON(r, &pipelineRequest)
+	if err != nil {
+		return 400, err
+	}
+
+	status, err = updatePipeline(id, pipelineRequest, db, logForwarder)
+	if err != nil {
+		return status, err
+	}
+
+	return writePipeline(w, 200, id, db)
+}
+
+func APIDelete(w http.ResponseWriter, r *http.Request, db *sqlx.DB, logForwarder *forwarder.Forwarder) (status int, err error) {
+	id, err := getPipelineID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	status, err = deletePipeline(id, db, logForwarder)
+	if err != nil {
+		return status, err
+	}
+
+	err = api.WriteJSON(w, 204, nil)
+	if err != nil {
+		return 500, err
+	}
+
+	return 204, nil
+}
+
+func writePipeline(w http.ResponseWriter, status int, id int, db *sqlx.DB) (int, error) {
+	pipeline, err := getPipeline(id, db)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 404, errPipelineNotFound
+		}
+		return 500, err
+	}
+
+	err = api.WriteJSON(w, status, pipeline)
+	if err != nil {
+		return 500, err
+	}
+
+	return status, nil
+}
@@ -0,0 +1,75 @@
+package forwarding
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/forwarder"
+	"github.com/jmoiron/sqlx"
+)
+
+func Create(w http.ResponseWriter, r *http.Request, db *sqlx.DB, logForwarder *forwarder.Forwarder) (status int, err error) {
+	pipelineRequest, err := parsePipelineRequest(r)
+	if err != nil {
+		return 400, err
+	}
+
+	_, status, err = createPipeline(pipelineRequest, db, logForwarder)
+	if err != nil {
+		return status, err
+	}
+
+	http.Redirect(w, r, "/dashboard/forwarding", http.StatusFound)
+
+	return 200, nil
+}
+
+func createPipeline(pipelineRequest pipelineRequest, db *sqlx.DB, logForwarder *forwarder.Forwarder) (id int, status int, err error) {
+	status, err = validatePipelineRequest(pipelineRequest)
+	if err != nil {
+		return 0, status, err
+	}
+
+	createPipelineQuery := squirrel.
+		Insert("Pipeline").
+		Columns("Name", "Kind", "URL", "Template", "IndexPattern", "RateLimit", "TagID", "Enabled").
+		Values(
+			pipelineRequest.Name,
+			pipelineRequest.Kind,
+			pipelineRequest.URL,
+			pipelineRequest.Template,
+			pipelineRequest.IndexPattern,
+			pipelineRequest.RateLimit,
+			pipelineRequest.TagID,
+			pipelineRequest.Enabled,
+		)
+
+	sql, args, err := createPipelineQuery.ToSql()
+	if err != nil {
+		return 0, 500, err
+	}
+
+	res, err := db.Exec(sql, args...)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return 0, 400, errPipelineExists
+		}
+		return 0, 500, err
+	}
+
+	lastInsertID, err := res.LastInsertId()
+	if err != nil {
+		return 0, 500, err
+	}
+
+	err = logForwarder.Reload()
+	if err != nil {
+		return 0, 500, err
+	}
+
+	log.Info("pipeline was created", "name", pipelineRequest.Name)
+
+	return int(lastInsertID), 200, nil
+}
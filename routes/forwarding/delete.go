@@ -0,0 +1,51 @@
+package forwarding
+
+import (
+	"net/http"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/forwarder"
+	"github.com/jmoiron/sqlx"
+)
+
+func Delete(w http.ResponseWriter, r *http.Request, db *sqlx.DB, logForwarder *forwarder.Forwarder) (status int, err error) {
+	id, err := getPipelineID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	status, err = deletePipeline(id, db, logForwarder)
+	if err != nil {
+		return status, err
+	}
+
+	http.Redirect(w, r, "/dashboard/forwarding", http.StatusFound)
+
+	return 200, nil
+}
+
+func deletePipeline(id int, db *sqlx.DB, logForwarder *forwarder.Forwarder) (status int, err error) {
+	deletePipelineQuery := squirrel.
+		Delete("Pipeline").
+		Where(squirrel.Eq{"ID": id})
+
+	sql, args, err := deletePipelineQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	_, err = db.Exec(sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	err = logForwarder.Reload()
+	if err != nil {
+		return 500, err
+	}
+
+	log.Info("pipeline was deleted", "id", id)
+
+	return 200, nil
+}
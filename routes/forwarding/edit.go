@@ -0,0 +1,123 @@
+package forwarding
+
+import (
+	"database/sql"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/forwarder"
+	"github.com/ferretcode/pricetag/matcher"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+type editPipelineData struct {
+	User       types.User
+	Permission types.Permission
+	Form       pipelineForm
+}
+
+func RenderEditPipelinePage(w http.ResponseWriter, r *http.Request, db *sqlx.DB, tagMatcher *matcher.Matcher, templates *template.Template) (status int, err error) {
+	id, err := getPipelineID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	pipeline, err := getPipeline(id, db)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 404, errPipelineNotFound
+		}
+		return 500, err
+	}
+
+	err = templates.ExecuteTemplate(w, "edit_pipeline.html", editPipelineData{
+		User:       r.Context().Value("user").(types.User),
+		Permission: r.Context().Value("permission").(types.Permission),
+		Form: pipelineForm{
+			Pipeline:  pipeline,
+			Kinds:     forwarder.Kinds,
+			Tags:      tagMatcher.Tags(),
+			Variables: forwarder.Variables,
+		},
+	})
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
+
+func Update(w http.ResponseWriter, r *http.Request, db *sqlx.DB, logForwarder *forwarder.Forwarder) (status int, err error) {
+	id, err := getPipelineID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	pipelineRequest, err := parsePipelineRequest(r)
+	if err != nil {
+		return 400, err
+	}
+
+	status, err = updatePipeline(id, pipelineRequest, db, logForwarder)
+	if err != nil {
+		return status, err
+	}
+
+	http.Redirect(w, r, "/dashboard/forwarding", http.StatusFound)
+
+	return 200, nil
+}
+
+func updatePipeline(id int, pipelineRequest pipelineRequest, db *sqlx.DB, logForwarder *forwarder.Forwarder) (status int, err error) {
+	status, err = validatePipelineRequest(pipelineRequest)
+	if err != nil {
+		return status, err
+	}
+
+	updatePipelineQuery := squirrel.
+		Update("Pipeline").
+		Set("Name", pipelineRequest.Name).
+		Set("Kind", pipelineRequest.Kind).
+		Set("URL", pipelineRequest.URL).
+		Set("Template", pipelineRequest.Template).
+		Set("IndexPattern", pipelineRequest.IndexPattern).
+		Set("RateLimit", pipelineRequest.RateLimit).
+		Set("TagID", pipelineRequest.TagID).
+		Set("Enabled", pipelineRequest.Enabled).
+		Where(squirrel.Eq{"ID": id})
+
+	query, args, err := updatePipelineQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	res, err := db.Exec(query, args...)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return 400, errPipelineExists
+		}
+		return 500, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 500, err
+	}
+
+	if affected == 0 {
+		return 404, errPipelineNotFound
+	}
+
+	err = logForwarder.Reload()
+	if err != nil {
+		return 500, err
+	}
+
+	log.Info("pipeline was updated", "id", id, "name", pipelineRequest.Name)
+
+	return 200, nil
+}
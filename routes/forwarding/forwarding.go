@@ -0,0 +1,143 @@
+package forwarding
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ferretcode/pricetag/forwarder"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	errPipelineExists   = errors.New("a pipeline with that name already exists")
+	errPipelineNotFound = errors.New("pipeline not found")
+)
+
+type pipelineRequest struct {
+	Name         string `json:"name"`
+	Kind         string `json:"kind"`
+	URL          string `json:"url"`
+	Template     string `json:"template"`
+	IndexPattern string `json:"indexPattern"`
+	RateLimit    int    `json:"rateLimit"`
+	TagID        int    `json:"tagId"`
+	Enabled      bool   `json:"enabled"`
+}
+
+// pipelineForm is passed to the pipeline_form template
+type pipelineForm struct {
+	Pipeline  types.Pipeline
+	Kinds     []string
+	Tags      []types.Tag
+	Variables []string
+}
+
+func parsePipelineRequest(r *http.Request) (pipelineRequest, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return pipelineRequest{}, err
+	}
+
+	tagID := 0
+	rateLimit := 0
+
+	if value := r.PostFormValue("tag_id"); value != "" {
+		tagID, err = strconv.Atoi(value)
+		if err != nil {
+			return pipelineRequest{}, errors.New("invalid tag id")
+		}
+	}
+
+	if value := r.PostFormValue("rate_limit"); value != "" {
+		rateLimit, err = strconv.Atoi(value)
+		if err != nil {
+			return pipelineRequest{}, errors.New("invalid rate limit")
+		}
+	}
+
+	return pipelineRequest{
+		Name:         r.PostFormValue("name"),
+		Kind:         r.PostFormValue("kind"),
+		URL:          r.PostFormValue("url"),
+		Template:     r.PostFormValue("template"),
+		IndexPattern: r.PostFormValue("index_pattern"),
+		RateLimit:    rateLimit,
+		TagID:        tagID,
+		Enabled:      r.PostFormValue("enabled") == "on",
+	}, nil
+}
+
+func validatePipelineRequest(pr pipelineRequest) (status int, err error) {
+	if len(pr.Name) < 1 {
+		return 400, errors.New("your pipeline must have a name")
+	}
+
+	if len(pr.Name) > 32 {
+		return 400, errors.New("your pipeline name cannot be over 32 characters")
+	}
+
+	if !slices.Contains(forwarder.Kinds, pr.Kind) {
+		return 400, errors.New("your pipeline kind must be one of " + strings.Join(forwarder.Kinds, ", "))
+	}
+
+	destinationURL, err := url.Parse(pr.URL)
+	if err != nil || (destinationURL.Scheme != "http" && destinationURL.Scheme != "https") || destinationURL.Host == "" {
+		return 400, errors.New("your url must be an absolute http or https url")
+	}
+
+	switch pr.Kind {
+	case forwarder.KindWebhook:
+		err = forwarder.ValidateTemplate(pr.Template)
+		if err != nil {
+			return 400, err
+		}
+	case forwarder.KindElasticsearch:
+		err = forwarder.ValidateIndexPattern(pr.IndexPattern)
+		if err != nil {
+			return 400, err
+		}
+	case forwarder.KindSlack, forwarder.KindDiscord:
+		if pr.RateLimit < 1 || pr.RateLimit > forwarder.MaxRateLimit {
+			return 400, errors.New("your rate limit must be between 1 and " + strconv.Itoa(forwarder.MaxRateLimit) + " messages per minute")
+		}
+	}
+
+	return 200, nil
+}
+
+func getPipelineID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return 0, errors.New("invalid pipeline id")
+	}
+
+	return id, nil
+}
+
+func getPipeline(id int, db *sqlx.DB) (types.Pipeline, error) {
+	selectPipelineQuery := squirrel.
+		Select("*").
+		From("Pipeline").
+		Where(squirrel.Eq{"ID": id})
+
+	sql, args, err := selectPipelineQuery.ToSql()
+	if err != nil {
+		return types.Pipeline{}, err
+	}
+
+	pipeline := types.Pipeline{}
+
+	err = db.Get(&pipeline, sql, args...)
+	if err != nil {
+		return types.Pipeline{}, err
+	}
+
+	return pipeline, nil
+}
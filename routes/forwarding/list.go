@@ -0,0 +1,64 @@
+package forwarding
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/ferretcode/pricetag/forwarder"
+	"github.com/ferretcode/pricetag/matcher"
+	"github.com/ferretcode/pricetag/types"
+)
+
+type pipelineRow struct {
+	Pipeline types.Pipeline
+	TagName  string
+}
+
+type forwardingData struct {
+	User       types.User
+	Permission types.Permission
+	Pipelines  []pipelineRow
+	Form       pipelineForm
+}
+
+func RenderForwardingPage(w http.ResponseWriter, r *http.Request, logForwarder *forwarder.Forwarder, tagMatcher *matcher.Matcher, templates *template.Template) (status int, err error) {
+	tags := tagMatcher.Tags()
+
+	tagNames := map[int]string{}
+	for _, tag := range tags {
+		tagNames[tag.ID] = tag.Name
+	}
+
+	pipelines := logForwarder.Pipelines()
+
+	rows := make([]pipelineRow, len(pipelines))
+	for i := range pipelines {
+		rows[i] = pipelineRow{
+			Pipeline: pipelines[i],
+			TagName:  tagNames[pipelines[i].TagID],
+		}
+	}
+
+	err = templates.ExecuteTemplate(w, "forwarding.html", forwardingData{
+		User:       r.Context().Value("user").(types.User),
+		Permission: r.Context().Value("permission").(types.Permission),
+		Pipelines:  rows,
+		Form: pipelineForm{
+			Pipeline: types.Pipeline{
+				Template:     `{"message": "$LOG_CONTENT", "level": "$LOG_LEVEL", "timestamp": "$LOG_TIMESTAMP"}`,
+				Kind:         forwarder.KindWebhook,
+				IndexPattern: forwarder.DefaultIndexPattern,
+				RateLimit:    forwarder.DefaultRateLimit,
+				Enabled:      true,
+			},
+			Kinds:     forwarder.Kinds,
+			Tags:      tags,
+			Variables: forwarder.Variables,
+		},
+	})
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
@@ -0,0 +1,57 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/ferretcode/pricetag/api"
+	"github.com/ferretcode/pricetag/sources"
+)
+
+type healthResponse struct {
+	// Status is ok, degraded while a source reconnects, or failed
+	Status  string         `json:"status"`
+	Sources []sourceHealth `json:"sources"`
+}
+
+// sourceHealth is the state of one source connection. Names and errors
+// reveal the projects and environments being streamed, so they are left to
+// the dashboard
+type sourceHealth struct {
+	State sources.State `json:"state"`
+}
+
+// Health reports the connection of every source for uptime checks. It is
+// public, so only the states are reported. A failed source responds with 503
+func Health(w http.ResponseWriter, r *http.Request, active []sources.Source) (status int, err error) {
+	statuses := sources.Statuses(active)
+
+	response := healthResponse{
+		Status:  "ok",
+		Sources: make([]sourceHealth, 0, len(statuses)),
+	}
+
+	status = http.StatusOK
+
+	for _, sourceStatus := range statuses {
+		response.Sources = append(response.Sources, sourceHealth{
+			State: sourceStatus.State,
+		})
+
+		switch sourceStatus.State {
+		case sources.StateReconnecting:
+			if response.Status == "ok" {
+				response.Status = "degraded"
+			}
+		case sources.StateFailed:
+			response.Status = "failed"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	err = api.WriteJSON(w, status, response)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return status, nil
+}
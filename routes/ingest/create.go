@@ -0,0 +1,66 @@
+package ingest
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/token"
+	"github.com/jmoiron/sqlx"
+)
+
+func Create(w http.ResponseWriter, r *http.Request, db *sqlx.DB, templates *template.Template) (status int, err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return 500, err
+	}
+
+	name := r.PostFormValue("name")
+
+	if len(name) < 1 {
+		return 400, errors.New("your ingest source must have a name")
+	}
+
+	if len(name) > 32 {
+		return 400, errors.New("your ingest source name cannot be over 32 characters")
+	}
+
+	ingestKey, err := token.Generate()
+	if err != nil {
+		return 500, err
+	}
+
+	createIngestSourceQuery := squirrel.
+		Insert("IngestSource").
+		Columns("Name", "KeyHash", "MessageKey", "LevelKey", "TimestampKey", "CreatedAt").
+		Values(
+			name,
+			token.Hash(ingestKey),
+			strings.Join(splitKeys(r.PostFormValue("message_key")), ","),
+			strings.Join(splitKeys(r.PostFormValue("level_key")), ","),
+			strings.Join(splitKeys(r.PostFormValue("timestamp_key")), ","),
+			time.Now().Unix(),
+		)
+
+	sql, args, err := createIngestSourceQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	_, err = db.Exec(sql, args...)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return 400, errIngestSourceExists
+		}
+		return 500, err
+	}
+
+	log.Info("ingest source was created", "name", name)
+
+	// rendered rather than redirected so the key is shown exactly once
+	return renderIngestPage(w, r, db, templates, name, ingestKey)
+}
@@ -0,0 +1,36 @@
+package ingest
+
+import (
+	"net/http"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/jmoiron/sqlx"
+)
+
+func Delete(w http.ResponseWriter, r *http.Request, db *sqlx.DB) (status int, err error) {
+	id, err := getIngestSourceID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	deleteIngestSourceQuery := squirrel.
+		Delete("IngestSource").
+		Where(squirrel.Eq{"ID": id})
+
+	sql, args, err := deleteIngestSourceQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	_, err = db.Exec(sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	log.Info("ingest source was deleted", "id", id)
+
+	http.Redirect(w, r, "/dashboard/ingest", http.StatusFound)
+
+	return 200, nil
+}
@@ -0,0 +1,101 @@
+package ingest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ferretcode/pricetag/sources"
+	"github.com/ferretcode/pricetag/token"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+var errIngestSourceExists = errors.New("an ingest source with that name already exists")
+
+func getIngestSourceID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return 0, errors.New("invalid ingest source id")
+	}
+
+	return id, nil
+}
+
+func getIngestSources(db *sqlx.DB) ([]types.IngestSource, error) {
+	selectIngestSourcesQuery := squirrel.
+		Select("*").
+		From("IngestSource").
+		OrderBy("Name")
+
+	sql, args, err := selectIngestSourcesQuery.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	ingestSources := []types.IngestSource{}
+
+	err = db.Select(&ingestSources, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ingestSources, nil
+}
+
+// keysFor returns the json keys of the source, falling back to the defaults
+// for the ones it leaves empty
+func keysFor(ingestSource types.IngestSource) sources.Keys {
+	keys := sources.DefaultKeys
+
+	if value := splitKeys(ingestSource.MessageKey); len(value) > 0 {
+		keys.Message = value
+	}
+
+	if value := splitKeys(ingestSource.LevelKey); len(value) > 0 {
+		keys.Level = value
+	}
+
+	if value := splitKeys(ingestSource.TimestampKey); len(value) > 0 {
+		keys.Timestamp = value
+	}
+
+	return keys
+}
+
+func splitKeys(value string) []string {
+	keys := []string{}
+
+	for _, key := range strings.Split(value, ",") {
+		key = strings.TrimSpace(key)
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+func getIngestSourceByKey(ingestKey string, db *sqlx.DB) (types.IngestSource, error) {
+	selectIngestSourceQuery := squirrel.
+		Select("*").
+		From("IngestSource").
+		Where(squirrel.Eq{"KeyHash": token.Hash(ingestKey)})
+
+	sql, args, err := selectIngestSourceQuery.ToSql()
+	if err != nil {
+		return types.IngestSource{}, err
+	}
+
+	ingestSource := types.IngestSource{}
+
+	err = db.Get(&ingestSource, sql, args...)
+	if err != nil {
+		return types.IngestSource{}, err
+	}
+
+	return ingestSource, nil
+}
@@ -0,0 +1,46 @@
+package ingest
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/ferretcode/pricetag/sources"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+type ingestData struct {
+	User          types.User
+	Permission    types.Permission
+	IngestSources []types.IngestSource
+	DefaultKeys   sources.Keys
+	// NewKey is only set right after a source is created, it can't be
+	// recovered afterwards
+	NewKey     string
+	NewKeyName string
+}
+
+func RenderIngestPage(w http.ResponseWriter, r *http.Request, db *sqlx.DB, templates *template.Template) (status int, err error) {
+	return renderIngestPage(w, r, db, templates, "", "")
+}
+
+func renderIngestPage(w http.ResponseWriter, r *http.Request, db *sqlx.DB, templates *template.Template, newKeyName string, newKey string) (status int, err error) {
+	ingestSources, err := getIngestSources(db)
+	if err != nil {
+		return 500, err
+	}
+
+	err = templates.ExecuteTemplate(w, "ingest.html", ingestData{
+		User:          r.Context().Value("user").(types.User),
+		Permission:    r.Context().Value("permission").(types.Permission),
+		IngestSources: ingestSources,
+		DefaultKeys:   sources.DefaultKeys,
+		NewKey:        newKey,
+		NewKeyName:    newKeyName,
+	})
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
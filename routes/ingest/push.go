@@ -0,0 +1,144 @@
+package ingest
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ferretcode/pricetag/api"
+	"github.com/ferretcode/pricetag/sink"
+	"github.com/ferretcode/pricetag/sources"
+	"github.com/ferretcode/pricetag/token"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	maxBodySize = 10 << 20
+	maxLogs     = 10000
+	// sinkTimeout is how long a push waits for room in the sink before it is
+	// turned away with a 503
+	sinkTimeout = 5 * time.Second
+)
+
+var (
+	errInvalidIngestKey = errors.New("invalid ingest key")
+	errSinkSaturated    = errors.New("pricetag is busy, retry later")
+)
+
+type pushResponse struct {
+	Accepted int `json:"accepted"`
+}
+
+// Push accepts a json object, a json array of objects or ndjson. The ingest
+// key is sent as "Authorization: Bearer <key>" or "X-Ingest-Key: <key>"
+func Push(w http.ResponseWriter, r *http.Request, db *sqlx.DB, logSink *sink.Sink) (status int, err error) {
+	ingestKey := token.IngestKey(r)
+	if ingestKey == "" {
+		return 401, errInvalidIngestKey
+	}
+
+	ingestSource, err := getIngestSourceByKey(ingestKey, db)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 401, errInvalidIngestKey
+		}
+		return 500, err
+	}
+
+	logs, err := decodeLogs(http.MaxBytesReader(w, r.Body, maxBodySize), keysFor(ingestSource))
+	if err != nil {
+		maxBytesError := &http.MaxBytesError{}
+		if errors.As(err, &maxBytesError) {
+			return 413, errors.New("the request body cannot be over " + strconv.Itoa(maxBodySize) + " bytes")
+		}
+		return 400, err
+	}
+
+	for i := range logs {
+		logs[i].ServiceID = "ingest:" + ingestSource.Name
+		logs[i].ServiceName = ingestSource.Name
+	}
+
+	timer := time.NewTimer(sinkTimeout)
+	defer timer.Stop()
+
+	// a full sink holds the request open, and past sinkTimeout the client
+	// is told to back off instead of the logs being dropped silently
+	select {
+	case logSink.NewLog <- logs:
+	case <-timer.C:
+		w.Header().Set("Retry-After", strconv.Itoa(int(sinkTimeout.Seconds())))
+		return 503, errSinkSaturated
+	case <-r.Context().Done():
+		return 503, r.Context().Err()
+	}
+
+	err = api.WriteJSON(w, 202, pushResponse{
+		Accepted: len(logs),
+	})
+	if err != nil {
+		return 500, err
+	}
+
+	return 202, nil
+}
+
+// decodeLogs reads a stream of json values, each an object or an array of
+// objects. A single object, an array and ndjson are all such streams
+func decodeLogs(body io.Reader, keys sources.Keys) ([]types.Log, error) {
+	decoder := json.NewDecoder(body)
+	logs := []types.Log{}
+
+	for {
+		value := json.RawMessage{}
+
+		err := decoder.Decode(&value)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid json body: %w", err)
+		}
+
+		objects := []json.RawMessage{value}
+
+		if bytes.HasPrefix(value, []byte("[")) {
+			objects = nil
+
+			err = json.Unmarshal(value, &objects)
+			if err != nil {
+				return nil, errors.New("invalid json body: " + err.Error())
+			}
+		}
+
+		for _, object := range objects {
+			if !bytes.HasPrefix(bytes.TrimSpace(object), []byte("{")) {
+				return nil, errors.New("every log must be a json object")
+			}
+
+			log, err := sources.ParseJSON(object, keys)
+			if err != nil {
+				return nil, errors.New("invalid json body: " + err.Error())
+			}
+
+			logs = append(logs, log)
+		}
+
+		if len(logs) > maxLogs {
+			return nil, errors.New("a request cannot contain over " + strconv.Itoa(maxLogs) + " logs")
+		}
+	}
+
+	if len(logs) == 0 {
+		return nil, errors.New("the request body contains no logs")
+	}
+
+	return logs, nil
+}
@@ -0,0 +1,70 @@
+package invites
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/token"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+func Create(w http.ResponseWriter, r *http.Request, db *sqlx.DB, templates *template.Template) (status int, err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return 500, err
+	}
+
+	expiryHours, err := strconv.Atoi(r.PostFormValue("expiry_hours"))
+	if err != nil || !slices.Contains(expiryOptions, expiryHours) {
+		return 400, errors.New("invalid invite expiry")
+	}
+
+	inviteToken, err := token.Generate()
+	if err != nil {
+		return 500, err
+	}
+
+	currentUser := r.Context().Value("user").(types.User)
+	now := time.Now()
+
+	createInviteQuery := squirrel.
+		Insert("Invite").
+		Columns(
+			"TokenHash",
+			"Admin", "ManageServices", "ManageTags", "ManageForwarding", "ViewLogs",
+			"CreatedBy", "CreatedAt", "ExpiresAt",
+		).
+		Values(
+			token.Hash(inviteToken),
+			r.PostFormValue("admin") == "on",
+			r.PostFormValue("manage_services") == "on",
+			r.PostFormValue("manage_tags") == "on",
+			r.PostFormValue("manage_forwarding") == "on",
+			r.PostFormValue("view_logs") == "on",
+			currentUser.ID,
+			now.Unix(),
+			now.Add(time.Duration(expiryHours)*time.Hour).Unix(),
+		)
+
+	sql, args, err := createInviteQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	_, err = db.Exec(sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	log.Info("invite was created", "by", currentUser.ID, "expiry_hours", expiryHours)
+
+	// rendered rather than redirected so the link is shown exactly once
+	return renderInvitesPage(w, r, db, templates, inviteURL(r, inviteToken))
+}
@@ -0,0 +1,36 @@
+package invites
+
+import (
+	"net/http"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/jmoiron/sqlx"
+)
+
+func Delete(w http.ResponseWriter, r *http.Request, db *sqlx.DB) (status int, err error) {
+	id, err := getInviteID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	deleteInviteQuery := squirrel.
+		Delete("Invite").
+		Where(squirrel.Eq{"ID": id})
+
+	sql, args, err := deleteInviteQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	_, err = db.Exec(sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	log.Info("invite was deleted", "id", id)
+
+	http.Redirect(w, r, "/dashboard/invites", http.StatusFound)
+
+	return 200, nil
+}
@@ -0,0 +1,63 @@
+package invites
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+// expiryOptions are the invite lifetimes offered in the dashboard, in hours
+var expiryOptions = []int{1, 24, 72, 168}
+
+func getInviteID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return 0, errors.New("invalid invite id")
+	}
+
+	return id, nil
+}
+
+func getInvites(db *sqlx.DB) ([]types.Invite, error) {
+	selectInvitesQuery := squirrel.
+		Select("*").
+		From("Invite").
+		OrderBy("CreatedAt DESC")
+
+	sql, args, err := selectInvitesQuery.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	invites := []types.Invite{}
+
+	err = db.Select(&invites, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return invites, nil
+}
+
+// inviteURL builds the registration link for a token. PUBLIC_URL should be
+// set when pricetag sits behind a proxy that rewrites the host
+func inviteURL(r *http.Request, inviteToken string) string {
+	baseURL := os.Getenv("PUBLIC_URL")
+
+	if baseURL == "" {
+		scheme := "http"
+		if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+
+		baseURL = scheme + "://" + r.Host
+	}
+
+	return baseURL + "/user/create?invite=" + inviteToken
+}
@@ -0,0 +1,43 @@
+package invites
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+type invitesData struct {
+	User          types.User
+	Permission    types.Permission
+	Invites       []types.Invite
+	ExpiryOptions []int
+	// NewInviteURL is only set right after an invite is created, the token
+	// can't be recovered afterwards
+	NewInviteURL string
+}
+
+func RenderInvitesPage(w http.ResponseWriter, r *http.Request, db *sqlx.DB, templates *template.Template) (status int, err error) {
+	return renderInvitesPage(w, r, db, templates, "")
+}
+
+func renderInvitesPage(w http.ResponseWriter, r *http.Request, db *sqlx.DB, templates *template.Template, newInviteURL string) (status int, err error) {
+	invites, err := getInvites(db)
+	if err != nil {
+		return 500, err
+	}
+
+	err = templates.ExecuteTemplate(w, "invites.html", invitesData{
+		User:          r.Context().Value("user").(types.User),
+		Permission:    r.Context().Value("permission").(types.Permission),
+		Invites:       invites,
+		ExpiryOptions: expiryOptions,
+		NewInviteURL:  newInviteURL,
+	})
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
@@ -0,0 +1,29 @@
+package logs
+
+import (
+	"net/http"
+
+	"github.com/ferretcode/pricetag/api"
+	"github.com/ferretcode/pricetag/logstore"
+)
+
+// APIQuery takes the same query string as the history page and returns a
+// logstore.Page, pass its next value back as cursor for the following page
+func APIQuery(w http.ResponseWriter, r *http.Request, store logstore.Store) (status int, err error) {
+	query, err := parseQuery(r)
+	if err != nil {
+		return 400, err
+	}
+
+	page, err := store.Query(r.Context(), query)
+	if err != nil {
+		return 500, err
+	}
+
+	err = api.WriteJSON(w, 200, page)
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
@@ -0,0 +1,76 @@
+package logs
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/ferretcode/pricetag/logstore"
+	"github.com/ferretcode/pricetag/matcher"
+	"github.com/ferretcode/pricetag/types"
+)
+
+type historyData struct {
+	User       types.User
+	Permission types.Permission
+	Tags       []types.Tag
+	Levels     []string
+
+	Level     string
+	ServiceID string
+	Tag       string
+	Keyword   string
+	From      string
+	To        string
+
+	Logs []types.Log
+	// NextURL links to the next page, empty on the last page
+	NextURL string
+}
+
+func RenderHistoryPage(w http.ResponseWriter, r *http.Request, store logstore.Store, tagMatcher *matcher.Matcher, templates *template.Template) (status int, err error) {
+	query, err := parseQuery(r)
+	if err != nil {
+		return 400, err
+	}
+
+	page, err := store.Query(r.Context(), query)
+	if err != nil {
+		return 500, err
+	}
+
+	data := historyData{
+		User:       r.Context().Value("user").(types.User),
+		Permission: r.Context().Value("permission").(types.Permission),
+		Tags:       tagMatcher.Tags(),
+		Levels:     levels,
+
+		Level:     query.Level,
+		ServiceID: query.ServiceID,
+		Tag:       query.Tag,
+		Keyword:   query.Keyword,
+
+		Logs: page.Logs,
+	}
+
+	if !query.From.IsZero() {
+		data.From = query.From.Format(timeInputLayout)
+	}
+
+	if !query.To.IsZero() {
+		data.To = query.To.Format(timeInputLayout)
+	}
+
+	if page.Next != "" {
+		next := r.URL.Query()
+		next.Set("cursor", page.Next)
+
+		data.NextURL = "/dashboard/logs/history?" + next.Encode()
+	}
+
+	err = templates.ExecuteTemplate(w, "history.html", data)
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
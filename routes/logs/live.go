@@ -0,0 +1,92 @@
+package logs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/matcher"
+	"github.com/ferretcode/pricetag/stream"
+	"github.com/ferretcode/pricetag/types"
+)
+
+const heartbeatInterval = 15 * time.Second
+
+type liveData struct {
+	User       types.User
+	Permission types.Permission
+	Tags       []types.Tag
+	Levels     []string
+}
+
+func RenderLivePage(w http.ResponseWriter, r *http.Request, tagMatcher *matcher.Matcher, templates *template.Template) (status int, err error) {
+	err = templates.ExecuteTemplate(w, "live.html", liveData{
+		User:       r.Context().Value("user").(types.User),
+		Permission: r.Context().Value("permission").(types.Permission),
+		Tags:       tagMatcher.Tags(),
+		Levels:     levels,
+	})
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
+
+// Stream sends new logs to the browser as server-sent events until the
+// client disconnects
+func Stream(w http.ResponseWriter, r *http.Request, hub *stream.Hub) (status int, err error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return 500, errors.New("streaming is not supported")
+	}
+
+	values := r.URL.Query()
+
+	client := hub.Subscribe(stream.Filter{
+		Tag:       values.Get("tag"),
+		ServiceID: values.Get("service"),
+		Level:     values.Get("level"),
+	})
+	defer hub.Unsubscribe(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	// tell the browser the stream is open before the first log arrives
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return 200, nil
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case logs := <-client.Logs:
+			data, err := json.Marshal(logs)
+			if err != nil {
+				// the response has started, so the error can only be logged
+				log.Error("error encoding live logs", "err", err)
+				continue
+			}
+
+			_, err = fmt.Fprintf(w, "event: logs\ndata: %s\n\n", data)
+			if err != nil {
+				return 200, nil
+			}
+
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package logs
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ferretcode/pricetag/logstore"
+)
+
+// timeInputLayout matches the value of a datetime-local input
+const timeInputLayout = "2006-01-02T15:04"
+
+// levels are the severities offered as filters
+var levels = []string{"debug", "info", "warn", "error"}
+
+// parseQuery reads a logstore.Query from the url query string. Times are
+// interpreted as UTC
+func parseQuery(r *http.Request) (logstore.Query, error) {
+	values := r.URL.Query()
+
+	query := logstore.Query{
+		Level:     values.Get("level"),
+		ServiceID: values.Get("service"),
+		Tag:       values.Get("tag"),
+		Keyword:   values.Get("q"),
+		Cursor:    values.Get("cursor"),
+	}
+
+	var err error
+
+	if from := values.Get("from"); from != "" {
+		query.From, err = parseTime(from)
+		if err != nil {
+			return logstore.Query{}, errors.New("invalid from time")
+		}
+	}
+
+	if to := values.Get("to"); to != "" {
+		query.To, err = parseTime(to)
+		if err != nil {
+			return logstore.Query{}, errors.New("invalid to time")
+		}
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return logstore.Query{}, errors.New("invalid limit")
+		}
+	}
+
+	return query, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	parsed, err := time.Parse(timeInputLayout, value)
+	if err == nil {
+		return parsed, nil
+	}
+
+	return time.Parse(time.RFC3339Nano, value)
+}
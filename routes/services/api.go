@@ -0,0 +1,72 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/ferretcode/pricetag/api"
+	"github.com/ferretcode/pricetag/sources/railway"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+type servicesResponse struct {
+	Services []serviceRow `json:"services"`
+	// RailwayError is set when only the tracked services could be listed
+	RailwayError string `json:"railwayError,omitempty"`
+}
+
+type trackRequest struct {
+	ServiceID string `json:"serviceId"`
+}
+
+func APIList(w http.ResponseWriter, r *http.Request, db *sqlx.DB, gql *railway.GraphQLConfig, config *railway.Config) (status int, err error) {
+	services, railwayError, err := listServices(r.Context(), db, gql, config)
+	if err != nil {
+		return 500, err
+	}
+
+	err = api.WriteJSON(w, 200, servicesResponse{
+		Services:     services,
+		RailwayError: railwayError,
+	})
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
+
+func APITrack(w http.ResponseWriter, r *http.Request, db *sqlx.DB, gql *railway.GraphQLConfig, config *railway.Config) (status int, err error) {
+	trackRequest := trackRequest{}
+
+	err = api.DecodeJSON(r, &trackRequest)
+	if err != nil {
+		return 400, err
+	}
+
+	service, status, err := trackService(r.Context(), trackRequest.ServiceID, db, gql, config)
+	if err != nil {
+		return status, err
+	}
+
+	err = api.WriteJSON(w, 201, service)
+	if err != nil {
+		return 500, err
+	}
+
+	return 201, nil
+}
+
+func APIUntrack(w http.ResponseWriter, r *http.Request, db *sqlx.DB, config *railway.Config) (status int, err error) {
+	status, err = untrackService(chi.URLParam(r, "serviceID"), db, config)
+	if err != nil {
+		return status, err
+	}
+
+	err = api.WriteJSON(w, 204, nil)
+	if err != nil {
+		return 500, err
+	}
+
+	return 204, nil
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/api"
+	"github.com/ferretcode/pricetag/sources/railway"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	errEnvironmentExists   = errors.New("that environment is already streamed")
+	errEnvironmentNotFound = errors.New("environment not found")
+)
+
+type environmentsResponse struct {
+	Environments []types.RailwayEnvironment `json:"environments"`
+}
+
+type environmentRequest struct {
+	EnvironmentID string `json:"environmentId"`
+}
+
+func GetEnvironments(db *sqlx.DB) ([]types.RailwayEnvironment, error) {
+	selectEnvironmentsQuery := squirrel.
+		Select("*").
+		From("RailwayEnvironment").
+		OrderBy("ProjectName", "Name")
+
+	sql, args, err := selectEnvironmentsQuery.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	environments := []types.RailwayEnvironment{}
+
+	err = db.Select(&environments, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return environments, nil
+}
+
+func AddEnvironment(w http.ResponseWriter, r *http.Request, db *sqlx.DB, gql *railway.GraphQLConfig, config *railway.Config) (status int, err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return 500, err
+	}
+
+	_, status, err = CreateEnvironment(r.Context(), r.PostFormValue("environment_id"), db, gql, config)
+	if err != nil {
+		return status, err
+	}
+
+	http.Redirect(w, r, "/dashboard/services", http.StatusFound)
+
+	return 200, nil
+}
+
+func RemoveEnvironment(w http.ResponseWriter, r *http.Request, db *sqlx.DB, config *railway.Config) (status int, err error) {
+	status, err = deleteEnvironment(chi.URLParam(r, "environmentID"), db, config)
+	if err != nil {
+		return status, err
+	}
+
+	http.Redirect(w, r, "/dashboard/services", http.StatusFound)
+
+	return 200, nil
+}
+
+func APIListEnvironments(w http.ResponseWriter, r *http.Request, db *sqlx.DB) (status int, err error) {
+	environments, err := GetEnvironments(db)
+	if err != nil {
+		return 500, err
+	}
+
+	err = api.WriteJSON(w, 200, environmentsResponse{
+		Environments: environments,
+	})
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
+
+func APIAddEnvironment(w http.ResponseWriter, r *http.Request, db *sqlx.DB, gql *railway.GraphQLConfig, config *railway.Config) (status int, err error) {
+	environmentRequest := environmentRequest{}
+
+	err = api.DecodeJSON(r, &environmentRequest)
+	if err != nil {
+		return 400, err
+	}
+
+	environment, status, err := CreateEnvironment(r.Context(), environmentRequest.EnvironmentID, db, gql, config)
+	if err != nil {
+		return status, err
+	}
+
+	err = api.WriteJSON(w, 201, environment)
+	if err != nil {
+		return 500, err
+	}
+
+	return 201, nil
+}
+
+func APIRemoveEnvironment(w http.ResponseWriter, r *http.Request, db *sqlx.DB, config *railway.Config) (status int, err error) {
+	status, err = deleteEnvironment(chi.URLParam(r, "environmentID"), db, config)
+	if err != nil {
+		return status, err
+	}
+
+	err = api.WriteJSON(w, 204, nil)
+	if err != nil {
+		return 500, err
+	}
+
+	return 204, nil
+}
+
+// CreateEnvironment looks up the names of an environment and its project on
+// Railway and starts streaming it
+func CreateEnvironment(ctx context.Context, environmentID string, db *sqlx.DB, gql *railway.GraphQLConfig, config *railway.Config) (environment types.RailwayEnvironment, status int, err error) {
+	if gql == nil || config == nil {
+		return types.RailwayEnvironment{}, 503, errors.New("railway is not configured")
+	}
+
+	environmentID = strings.TrimSpace(environmentID)
+	if environmentID == "" {
+		return types.RailwayEnvironment{}, 400, errors.New("an environment id is required")
+	}
+
+	found, err := gql.LookupEnvironment(ctx, environmentID)
+	if err != nil {
+		return types.RailwayEnvironment{}, 502, err
+	}
+
+	createEnvironmentQuery := squirrel.
+		Insert("RailwayEnvironment").
+		Columns("EnvironmentID", "Name", "ProjectID", "ProjectName", "CreatedAt").
+		Values(found.EnvironmentID, found.Name, found.ProjectID, found.ProjectName, time.Now().Unix()).
+		Suffix("RETURNING *")
+
+	sql, args, err := createEnvironmentQuery.ToSql()
+	if err != nil {
+		return types.RailwayEnvironment{}, 500, err
+	}
+
+	err = db.Get(&environment, sql, args...)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return types.RailwayEnvironment{}, 409, errEnvironmentExists
+		}
+		return types.RailwayEnvironment{}, 500, err
+	}
+
+	err = reloadEnvironments(db, config)
+	if err != nil {
+		return types.RailwayEnvironment{}, 500, err
+	}
+
+	log.Info("railway environment is now streamed", "environment", environment.Name, "project", environment.ProjectName)
+
+	return environment, 201, nil
+}
+
+// deleteEnvironment stops streaming an environment. Its cursor is dropped
+// too, so adding it back starts from recent logs rather than backfilling
+// the whole gap
+func deleteEnvironment(environmentID string, db *sqlx.DB, config *railway.Config) (status int, err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return 500, err
+	}
+	defer tx.Rollback()
+
+	deleteEnvironmentQuery := squirrel.
+		Delete("RailwayEnvironment").
+		Where(squirrel.Eq{"EnvironmentID": environmentID})
+
+	sql, args, err := deleteEnvironmentQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	result, err := tx.Exec(sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	if deleted, err := result.RowsAffected(); err != nil || deleted == 0 {
+		return 404, errEnvironmentNotFound
+	}
+
+	deleteCursorQuery := squirrel.
+		Delete("RailwayCursor").
+		Where(squirrel.Eq{"EnvironmentID": environmentID})
+
+	sql, args, err = deleteCursorQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 500, err
+	}
+
+	err = reloadEnvironments(db, config)
+	if err != nil {
+		return 500, err
+	}
+
+	log.Info("railway environment is no longer streamed", "environment_id", environmentID)
+
+	return 200, nil
+}
+
+// reloadEnvironments makes changes to the RailwayEnvironment table take
+// effect on the running source
+func reloadEnvironments(db *sqlx.DB, config *railway.Config) error {
+	if config == nil {
+		return nil
+	}
+
+	environments, err := GetEnvironments(db)
+	if err != nil {
+		return err
+	}
+
+	config.SetEnvironments(environments)
+
+	return nil
+}
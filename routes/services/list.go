@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"sort"
+
+	"github.com/ferretcode/pricetag/sources/railway"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+type serviceRow struct {
+	ServiceID string `json:"serviceId"`
+	Name      string `json:"name"`
+	Tracked   bool   `json:"tracked"`
+	// Available is false when a tracked service no longer exists on Railway
+	Available bool `json:"available"`
+}
+
+type servicesData struct {
+	User         types.User
+	Permission   types.Permission
+	Services     []serviceRow
+	Environments []types.RailwayEnvironment
+	RailwayError string
+}
+
+func RenderServicesPage(w http.ResponseWriter, r *http.Request, db *sqlx.DB, gql *railway.GraphQLConfig, config *railway.Config, templates *template.Template) (status int, err error) {
+	services, railwayError, err := listServices(r.Context(), db, gql, config)
+	if err != nil {
+		return 500, err
+	}
+
+	environments, err := GetEnvironments(db)
+	if err != nil {
+		return 500, err
+	}
+
+	err = templates.ExecuteTemplate(w, "services.html", servicesData{
+		User:         r.Context().Value("user").(types.User),
+		Permission:   r.Context().Value("permission").(types.Permission),
+		Services:     services,
+		Environments: environments,
+		RailwayError: railwayError,
+	})
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
+
+// listServices merges the tracked services with the ones Railway reports.
+// Railway being unreachable is not an error, it is returned as railwayError
+// so the tracked services can still be listed
+func listServices(ctx context.Context, db *sqlx.DB, gql *railway.GraphQLConfig, config *railway.Config) (services []serviceRow, railwayError string, err error) {
+	tracked, err := GetTrackedServices(db)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows := map[string]*serviceRow{}
+
+	for _, service := range tracked {
+		rows[service.ServiceID] = &serviceRow{
+			ServiceID: service.ServiceID,
+			Name:      service.Name,
+			Tracked:   true,
+		}
+	}
+
+	if gql == nil || config == nil {
+		railwayError = "railway is not configured, set RAILWAY_API_KEY"
+	} else {
+		available, err := gql.GetServices(ctx, config)
+		if err != nil {
+			railwayError = err.Error()
+		}
+
+		for serviceID, name := range available {
+			row, ok := rows[serviceID]
+			if !ok {
+				row = &serviceRow{
+					ServiceID: serviceID,
+				}
+				rows[serviceID] = row
+			}
+
+			row.Name = name
+			row.Available = true
+		}
+	}
+
+	services = make([]serviceRow, 0, len(rows))
+	for _, row := range rows {
+		services = append(services, *row)
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+
+	return services, railwayError, nil
+}
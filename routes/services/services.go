@@ -0,0 +1,60 @@
+package services
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/ferretcode/pricetag/sources/railway"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+func GetTrackedServices(db *sqlx.DB) ([]types.Service, error) {
+	selectServicesQuery := squirrel.
+		Select("*").
+		From("Service").
+		OrderBy("Name")
+
+	sql, args, err := selectServicesQuery.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	services := []types.Service{}
+
+	err = db.Select(&services, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+func GetTrackedServiceIds(db *sqlx.DB) ([]string, error) {
+	services, err := GetTrackedServices(db)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceIds := make([]string, len(services))
+	for i := range services {
+		serviceIds[i] = services[i].ServiceID
+	}
+
+	return serviceIds, nil
+}
+
+// reloadConfig makes changes to the Service table take effect on the running
+// subscription
+func reloadConfig(db *sqlx.DB, config *railway.Config) error {
+	if config == nil {
+		return nil
+	}
+
+	serviceIds, err := GetTrackedServiceIds(db)
+	if err != nil {
+		return err
+	}
+
+	config.SetServiceIds(serviceIds)
+
+	return nil
+}
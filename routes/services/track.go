@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/sources/railway"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+func Track(w http.ResponseWriter, r *http.Request, db *sqlx.DB, gql *railway.GraphQLConfig, config *railway.Config) (status int, err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return 500, err
+	}
+
+	_, status, err = trackService(r.Context(), r.PostFormValue("service_id"), db, gql, config)
+	if err != nil {
+		return status, err
+	}
+
+	http.Redirect(w, r, "/dashboard/services", http.StatusFound)
+
+	return 200, nil
+}
+
+func Untrack(w http.ResponseWriter, r *http.Request, db *sqlx.DB, config *railway.Config) (status int, err error) {
+	status, err = untrackService(chi.URLParam(r, "serviceID"), db, config)
+	if err != nil {
+		return status, err
+	}
+
+	http.Redirect(w, r, "/dashboard/services", http.StatusFound)
+
+	return 200, nil
+}
+
+func trackService(ctx context.Context, serviceID string, db *sqlx.DB, gql *railway.GraphQLConfig, config *railway.Config) (service types.Service, status int, err error) {
+	if gql == nil || config == nil {
+		return types.Service{}, 503, errors.New("railway is not configured")
+	}
+
+	available, err := gql.GetServices(ctx, config)
+	if err != nil {
+		return types.Service{}, 502, err
+	}
+
+	name, ok := available[serviceID]
+	if !ok {
+		return types.Service{}, 404, errors.New("service not found in the railway project")
+	}
+
+	trackServiceQuery := squirrel.
+		Insert("Service").
+		Columns("ServiceID", "Name").
+		Values(serviceID, name).
+		Suffix("ON CONFLICT (ServiceID) DO UPDATE SET Name = excluded.Name RETURNING *")
+
+	sql, args, err := trackServiceQuery.ToSql()
+	if err != nil {
+		return types.Service{}, 500, err
+	}
+
+	err = db.Get(&service, sql, args...)
+	if err != nil {
+		return types.Service{}, 500, err
+	}
+
+	err = reloadConfig(db, config)
+	if err != nil {
+		return types.Service{}, 500, err
+	}
+
+	log.Info("service is now tracked", "service_id", serviceID, "name", name)
+
+	return service, 200, nil
+}
+
+func untrackService(serviceID string, db *sqlx.DB, config *railway.Config) (status int, err error) {
+	untrackServiceQuery := squirrel.
+		Delete("Service").
+		Where(squirrel.Eq{"ServiceID": serviceID})
+
+	sql, args, err := untrackServiceQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	_, err = db.Exec(sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	err = reloadConfig(db, config)
+	if err != nil {
+		return 500, err
+	}
+
+	log.Info("service is no longer tracked", "service_id", serviceID)
+
+	return 200, nil
+}
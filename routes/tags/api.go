@@ -0,0 +1,117 @@
+package tags
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/ferretcode/pricetag/api"
+	"github.com/ferretcode/pricetag/matcher"
+	"github.com/jmoiron/sqlx"
+)
+
+func APIList(w http.ResponseWriter, r *http.Request, tagMatcher *matcher.Matcher) (status int, err error) {
+	err = api.WriteJSON(w, 200, tagMatcher.Tags())
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
+
+func APIGet(w http.ResponseWriter, r *http.Request, db *sqlx.DB) (status int, err error) {
+	id, err := getTagID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	return writeTag(w, 200, id, db)
+}
+
+func APICreate(w http.ResponseWriter, r *http.Request, db *sqlx.DB, tagMatcher *matcher.Matcher) (status int, err error) {
+	tagRequest := tagRequest{}
+
+	err = api.DecodeJSON(r, &tagRequest)
+	if err != nil {
+		return 400, err
+	}
+
+	id, status, err := createTag(tagRequest, db, tagMatcher)
+	if err != nil {
+		return status, err
+	}
+
+	return writeTag(w, 201, id, db)
+}
+
+// APIUpdate changes the fields present in the body, the others keep their
+// current value
+func APIUpdate(w http.ResponseWriter, r *http.Request, db *sqlx.DB, tagMatcher *matcher.Matcher) (status int, err error) {
+	id, err := getTagID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	tag, err := getTag(id, db)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 404, errTagNotFound
+		}
+		return 500, err
+	}
+
+	tagRequest := tagRequest{
+		Name:           tag.Name,
+		Keyword:        tag.Keyword,
+		AttributeKey:   tag.AttributeKey,
+		AttributeValue: tag.AttributeValue,
+		ServiceID:      tag.ServiceID,
+	}
+
+	err = api.DecodeJSON(r, &tagRequest)
+	if err != nil {
+		return 400, err
+	}
+
+	status, err = updateTag(id, tagRequest, db, tagMatcher)
+	if err != nil {
+		return status, err
+	}
+
+	return writeTag(w, 200, id, db)
+}
+
+func APIDelete(w http.ResponseWriter, r *http.Request, db *sqlx.DB, tagMatcher *matcher.Matcher) (status int, err error) {
+	id, err := getTagID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	status, err = deleteTag(id, db, tagMatcher)
+	if err != nil {
+		return status, err
+	}
+
+	err = api.WriteJSON(w, 204, nil)
+	if err != nil {
+		return 500, err
+	}
+
+	return 204, nil
+}
+
+func writeTag(w http.ResponseWriter, status int, id int, db *sqlx.DB) (int, error) {
+	tag, err := getTag(id, db)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 404, errTagNotFound
+		}
+		return 500, err
+	}
+
+	err = api.WriteJSON(w, status, tag)
+	if err != nil {
+		return 500, err
+	}
+
+	return status, nil
+}
@@ -0,0 +1,72 @@
+package tags
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/matcher"
+	"github.com/jmoiron/sqlx"
+)
+
+func Create(w http.ResponseWriter, r *http.Request, db *sqlx.DB, tagMatcher *matcher.Matcher) (status int, err error) {
+	tagRequest, err := parseTagRequest(r)
+	if err != nil {
+		return 500, err
+	}
+
+	_, status, err = createTag(tagRequest, db, tagMatcher)
+	if err != nil {
+		return status, err
+	}
+
+	http.Redirect(w, r, "/dashboard/tags", http.StatusFound)
+
+	return 200, nil
+}
+
+func createTag(tagRequest tagRequest, db *sqlx.DB, tagMatcher *matcher.Matcher) (id int, status int, err error) {
+	status, err = validateTagRequest(tagRequest)
+	if err != nil {
+		return 0, status, err
+	}
+
+	createTagQuery := squirrel.
+		Insert("Tag").
+		Columns("Name", "Keyword", "AttributeKey", "AttributeValue", "ServiceID").
+		Values(
+			tagRequest.Name,
+			tagRequest.Keyword,
+			tagRequest.AttributeKey,
+			tagRequest.AttributeValue,
+			tagRequest.ServiceID,
+		)
+
+	sql, args, err := createTagQuery.ToSql()
+	if err != nil {
+		return 0, 500, err
+	}
+
+	res, err := db.Exec(sql, args...)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return 0, 400, errTagExists
+		}
+		return 0, 500, err
+	}
+
+	lastInsertID, err := res.LastInsertId()
+	if err != nil {
+		return 0, 500, err
+	}
+
+	err = tagMatcher.Reload()
+	if err != nil {
+		return 0, 500, err
+	}
+
+	log.Info("tag was created", "name", tagRequest.Name)
+
+	return int(lastInsertID), 200, nil
+}
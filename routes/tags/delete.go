@@ -0,0 +1,94 @@
+package tags
+
+import (
+	"net/http"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/matcher"
+	"github.com/jmoiron/sqlx"
+)
+
+func Delete(w http.ResponseWriter, r *http.Request, db *sqlx.DB, tagMatcher *matcher.Matcher) (status int, err error) {
+	id, err := getTagID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	status, err = deleteTag(id, db, tagMatcher)
+	if err != nil {
+		return status, err
+	}
+
+	http.Redirect(w, r, "/dashboard/tags", http.StatusFound)
+
+	return 200, nil
+}
+
+// deleteTag refuses to delete a tag that pipelines forward by. Clearing
+// their tag would make them forward every log instead
+func deleteTag(id int, db *sqlx.DB, tagMatcher *matcher.Matcher) (status int, err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return 500, err
+	}
+	defer tx.Rollback()
+
+	countPipelinesQuery := squirrel.
+		Select("COUNT(*)").
+		From("Pipeline").
+		Where(squirrel.Eq{"TagID": id})
+
+	sql, args, err := countPipelinesQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	pipelines := 0
+
+	err = tx.Get(&pipelines, sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	if pipelines > 0 {
+		return 409, errTagInUse
+	}
+
+	deleteTagQuery := squirrel.
+		Delete("Tag").
+		Where(squirrel.Eq{"ID": id})
+
+	sql, args, err = deleteTagQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	res, err := tx.Exec(sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 500, err
+	}
+
+	if affected == 0 {
+		return 404, errTagNotFound
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 500, err
+	}
+
+	err = tagMatcher.Reload()
+	if err != nil {
+		return 500, err
+	}
+
+	log.Info("tag was deleted", "id", id)
+
+	return 200, nil
+}
@@ -0,0 +1,114 @@
+package tags
+
+import (
+	"database/sql"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/matcher"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+type editTagData struct {
+	User       types.User
+	Permission types.Permission
+	Tag        types.Tag
+}
+
+func RenderEditTagPage(w http.ResponseWriter, r *http.Request, db *sqlx.DB, templates *template.Template) (status int, err error) {
+	id, err := getTagID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	tag, err := getTag(id, db)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 404, errTagNotFound
+		}
+		return 500, err
+	}
+
+	err = templates.ExecuteTemplate(w, "edit_tag.html", editTagData{
+		User:       r.Context().Value("user").(types.User),
+		Permission: r.Context().Value("permission").(types.Permission),
+		Tag:        tag,
+	})
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
+
+func Update(w http.ResponseWriter, r *http.Request, db *sqlx.DB, tagMatcher *matcher.Matcher) (status int, err error) {
+	id, err := getTagID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	tagRequest, err := parseTagRequest(r)
+	if err != nil {
+		return 500, err
+	}
+
+	status, err = updateTag(id, tagRequest, db, tagMatcher)
+	if err != nil {
+		return status, err
+	}
+
+	http.Redirect(w, r, "/dashboard/tags", http.StatusFound)
+
+	return 200, nil
+}
+
+func updateTag(id int, tagRequest tagRequest, db *sqlx.DB, tagMatcher *matcher.Matcher) (status int, err error) {
+	status, err = validateTagRequest(tagRequest)
+	if err != nil {
+		return status, err
+	}
+
+	updateTagQuery := squirrel.
+		Update("Tag").
+		Set("Name", tagRequest.Name).
+		Set("Keyword", tagRequest.Keyword).
+		Set("AttributeKey", tagRequest.AttributeKey).
+		Set("AttributeValue", tagRequest.AttributeValue).
+		Set("ServiceID", tagRequest.ServiceID).
+		Where(squirrel.Eq{"ID": id})
+
+	query, args, err := updateTagQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	res, err := db.Exec(query, args...)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return 400, errTagExists
+		}
+		return 500, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 500, err
+	}
+
+	if affected == 0 {
+		return 404, errTagNotFound
+	}
+
+	err = tagMatcher.Reload()
+	if err != nil {
+		return 500, err
+	}
+
+	log.Info("tag was updated", "id", id, "name", tagRequest.Name)
+
+	return 200, nil
+}
@@ -0,0 +1,28 @@
+package tags
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/ferretcode/pricetag/matcher"
+	"github.com/ferretcode/pricetag/types"
+)
+
+type tagsData struct {
+	User       types.User
+	Permission types.Permission
+	Tags       []types.Tag
+}
+
+func RenderTagsPage(w http.ResponseWriter, r *http.Request, tagMatcher *matcher.Matcher, templates *template.Template) (status int, err error) {
+	err = templates.ExecuteTemplate(w, "tags.html", tagsData{
+		User:       r.Context().Value("user").(types.User),
+		Permission: r.Context().Value("permission").(types.Permission),
+		Tags:       tagMatcher.Tags(),
+	})
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
@@ -0,0 +1,91 @@
+package tags
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	errTagExists   = errors.New("a tag with that name already exists")
+	errTagNotFound = errors.New("tag not found")
+	errTagInUse    = errors.New("this tag is used by forwarding pipelines, change or delete them first")
+)
+
+type tagRequest struct {
+	Name           string `json:"name"`
+	Keyword        string `json:"keyword"`
+	AttributeKey   string `json:"attributeKey"`
+	AttributeValue string `json:"attributeValue"`
+	ServiceID      string `json:"serviceId"`
+}
+
+func parseTagRequest(r *http.Request) (tagRequest, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return tagRequest{}, err
+	}
+
+	return tagRequest{
+		Name:           r.PostFormValue("name"),
+		Keyword:        r.PostFormValue("keyword"),
+		AttributeKey:   r.PostFormValue("attribute_key"),
+		AttributeValue: r.PostFormValue("attribute_value"),
+		ServiceID:      r.PostFormValue("service_id"),
+	}, nil
+}
+
+func validateTagRequest(tr tagRequest) (status int, err error) {
+	if len(tr.Name) < 1 {
+		return 400, errors.New("your tag must have a name")
+	}
+
+	if len(tr.Name) > 32 {
+		return 400, errors.New("your tag name cannot be over 32 characters")
+	}
+
+	if tr.Keyword == "" && tr.AttributeKey == "" && tr.ServiceID == "" {
+		return 400, errors.New("your tag must filter by a keyword, json attribute or service id")
+	}
+
+	if tr.AttributeValue != "" && tr.AttributeKey == "" {
+		return 400, errors.New("an attribute value requires an attribute key")
+	}
+
+	return 200, nil
+}
+
+func getTagID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return 0, errors.New("invalid tag id")
+	}
+
+	return id, nil
+}
+
+func getTag(id int, db *sqlx.DB) (types.Tag, error) {
+	selectTagQuery := squirrel.
+		Select("*").
+		From("Tag").
+		Where(squirrel.Eq{"ID": id})
+
+	sql, args, err := selectTagQuery.ToSql()
+	if err != nil {
+		return types.Tag{}, err
+	}
+
+	tag := types.Tag{}
+
+	err = db.Get(&tag, sql, args...)
+	if err != nil {
+		return types.Tag{}, err
+	}
+
+	return tag, nil
+}
@@ -0,0 +1,113 @@
+package tokens
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/token"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+func Create(w http.ResponseWriter, r *http.Request, db *sqlx.DB, templates *template.Template) (status int, err error) {
+	err = r.ParseForm()
+	if err != nil {
+		return 500, err
+	}
+
+	name := r.PostFormValue("name")
+
+	if len(name) < 1 {
+		return 400, errors.New("your token must have a name")
+	}
+
+	if len(name) > 32 {
+		return 400, errors.New("your token name cannot be over 32 characters")
+	}
+
+	expiryDays, err := strconv.Atoi(r.PostFormValue("expiry_days"))
+	if err != nil || !slices.Contains(expiryOptions, expiryDays) {
+		return 400, errors.New("invalid token expiry")
+	}
+
+	scope := types.APIToken{
+		Admin:            r.PostFormValue("admin") == "on",
+		ManageServices:   r.PostFormValue("manage_services") == "on",
+		ManageTags:       r.PostFormValue("manage_tags") == "on",
+		ManageForwarding: r.PostFormValue("manage_forwarding") == "on",
+		ViewLogs:         r.PostFormValue("view_logs") == "on",
+	}
+
+	if !scope.Admin && !scope.ManageServices && !scope.ManageTags && !scope.ManageForwarding && !scope.ViewLogs {
+		return 400, errors.New("your token must grant at least one permission")
+	}
+
+	permission := r.Context().Value("permission").(types.Permission)
+
+	if !canGrant(scope, permission) {
+		return 403, errors.New("your token cannot grant permissions you don't have")
+	}
+
+	apiToken, err := token.Generate()
+	if err != nil {
+		return 500, err
+	}
+
+	currentUser := r.Context().Value("user").(types.User)
+	now := time.Now()
+
+	expiresAt := int64(0)
+	if expiryDays != 0 {
+		expiresAt = now.AddDate(0, 0, expiryDays).Unix()
+	}
+
+	createTokenQuery := squirrel.
+		Insert("APIToken").
+		Columns(
+			"UserID", "Name", "TokenHash",
+			"Admin", "ManageServices", "ManageTags", "ManageForwarding", "ViewLogs",
+			"CreatedAt", "ExpiresAt",
+		).
+		Values(
+			currentUser.ID,
+			name,
+			token.Hash(apiToken),
+			scope.Admin,
+			scope.ManageServices,
+			scope.ManageTags,
+			scope.ManageForwarding,
+			scope.ViewLogs,
+			now.Unix(),
+			expiresAt,
+		)
+
+	sql, args, err := createTokenQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	_, err = db.Exec(sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	log.Info("api token was created", "user_id", currentUser.ID, "name", name)
+
+	// rendered rather than redirected so the token is shown exactly once
+	return renderTokensPage(w, r, db, templates, apiToken)
+}
+
+// canGrant reports whether every flag set on the token is held by the user
+func canGrant(scope types.APIToken, permission types.Permission) bool {
+	return (!scope.Admin || permission.IsAdmin()) &&
+		(!scope.ManageServices || permission.CanManageServices()) &&
+		(!scope.ManageTags || permission.CanManageTags()) &&
+		(!scope.ManageForwarding || permission.CanManageForwarding()) &&
+		(!scope.ViewLogs || permission.CanViewLogs())
+}
@@ -0,0 +1,50 @@
+package tokens
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+// Delete revokes one of the current user's tokens
+func Delete(w http.ResponseWriter, r *http.Request, db *sqlx.DB) (status int, err error) {
+	id, err := getTokenID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	currentUser := r.Context().Value("user").(types.User)
+
+	deleteTokenQuery := squirrel.
+		Delete("APIToken").
+		Where(squirrel.Eq{"ID": id, "UserID": currentUser.ID})
+
+	sql, args, err := deleteTokenQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	res, err := db.Exec(sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 500, err
+	}
+
+	if affected == 0 {
+		return 404, errors.New("token not found")
+	}
+
+	log.Info("api token was revoked", "id", id, "user_id", currentUser.ID)
+
+	http.Redirect(w, r, "/dashboard/tokens", http.StatusFound)
+
+	return 200, nil
+}
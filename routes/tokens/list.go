@@ -0,0 +1,45 @@
+package tokens
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+type tokensData struct {
+	User          types.User
+	Permission    types.Permission
+	Tokens        []types.APIToken
+	ExpiryOptions []int
+	// NewToken is only set right after a token is created, it can't be
+	// recovered afterwards
+	NewToken string
+}
+
+func RenderTokensPage(w http.ResponseWriter, r *http.Request, db *sqlx.DB, templates *template.Template) (status int, err error) {
+	return renderTokensPage(w, r, db, templates, "")
+}
+
+func renderTokensPage(w http.ResponseWriter, r *http.Request, db *sqlx.DB, templates *template.Template, newToken string) (status int, err error) {
+	user := r.Context().Value("user").(types.User)
+
+	tokens, err := getUserTokens(user.ID, db)
+	if err != nil {
+		return 500, err
+	}
+
+	err = templates.ExecuteTemplate(w, "tokens.html", tokensData{
+		User:          user,
+		Permission:    r.Context().Value("permission").(types.Permission),
+		Tokens:        tokens,
+		ExpiryOptions: expiryOptions,
+		NewToken:      newToken,
+	})
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
@@ -0,0 +1,47 @@
+package tokens
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+// expiryOptions are the token lifetimes offered in the dashboard, in days.
+// 0 never expires
+var expiryOptions = []int{7, 30, 90, 365, 0}
+
+func getTokenID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return 0, errors.New("invalid token id")
+	}
+
+	return id, nil
+}
+
+func getUserTokens(userID int, db *sqlx.DB) ([]types.APIToken, error) {
+	selectTokensQuery := squirrel.
+		Select("*").
+		From("APIToken").
+		Where(squirrel.Eq{"UserID": userID}).
+		OrderBy("CreatedAt DESC")
+
+	sql, args, err := selectTokensQuery.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := []types.APIToken{}
+
+	err = db.Select(&tokens, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
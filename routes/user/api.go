@@ -0,0 +1,26 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/ferretcode/pricetag/api"
+	"github.com/ferretcode/pricetag/types"
+)
+
+type meResponse struct {
+	User       types.User       `json:"user"`
+	Permission types.Permission `json:"permission"`
+}
+
+// APIMe returns the authenticated user, useful to check what a client may do
+func APIMe(w http.ResponseWriter, r *http.Request) (status int, err error) {
+	err = api.WriteJSON(w, 200, meResponse{
+		User:       r.Context().Value("user").(types.User),
+		Permission: r.Context().Value("permission").(types.Permission),
+	})
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
@@ -5,6 +5,7 @@ import (
 	"html/template"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -15,13 +16,29 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+var errInviteRequired = errors.New("registration is invite only, ask an admin for an invite link")
+
 type createUserRequest struct {
 	Username string
 	Password string
+	Invite   string
+}
+
+type createUserData struct {
+	Invite     string
+	InviteOnly bool
 }
 
-func RenderCreateUserPage(w http.ResponseWriter, r *http.Request, templates *template.Template) error {
-	err := templates.ExecuteTemplate(w, "create.html", nil)
+func RenderCreateUserPage(w http.ResponseWriter, r *http.Request, db *sqlx.DB, templates *template.Template) error {
+	usersExist, err := usersExist(db)
+	if err != nil {
+		return err
+	}
+
+	err = templates.ExecuteTemplate(w, "create.html", createUserData{
+		Invite:     r.URL.Query().Get("invite"),
+		InviteOnly: usersExist,
+	})
 	if err != nil {
 		return err
 	}
@@ -44,36 +61,13 @@ func Create(w http.ResponseWriter, r *http.Request, db *sqlx.DB, session *sessio
 
 	log.Info("form parsed successfully")
 
-	currentUsersQuery := squirrel.Select("*").From("User")
-
-	sql, _, err := currentUsersQuery.ToSql()
-	if err != nil {
-		return 500, err
-	}
-
-	res, err := db.Query(sql)
-	if err != nil {
-		return 500, err
-	}
-
-	exists := res.Next()
-
-	log.Info("are there existing users", "exists", exists)
-
-	admin := false
-
-	if !exists {
-		admin = true
-	}
-
-	log.Info("admin is set to", "admin", admin)
-
 	createUserRequest := createUserRequest{
 		Username: r.PostFormValue("username"),
 		Password: r.PostFormValue("password"),
+		Invite:   r.PostFormValue("invite"),
 	}
 
-	log.Info("create request populated", "username", createUserRequest.Username, "password", createUserRequest.Password)
+	log.Info("create request populated", "username", createUserRequest.Username)
 
 	status, err = validateUsernameAndPassword(createUserRequest.Username, createUserRequest.Password)
 	if err != nil {
@@ -82,30 +76,35 @@ func Create(w http.ResponseWriter, r *http.Request, db *sqlx.DB, session *sessio
 
 	log.Info("the user was successfully validated")
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(createUserRequest.Password), 14)
+	hash, err := HashPassword(createUserRequest.Password)
 	if err != nil {
 		return 500, err
 	}
 
-	log.Info("password was successfully hashed", "hash", string(hash))
+	log.Info("password was successfully hashed")
 
-	userID, err := createUserDBRecord(createUserRequest, string(hash), admin, db)
+	userID, status, err := createUserDBRecord(createUserRequest, hash, db)
 	if err != nil {
-		return 500, err
+		return status, err
 	}
 
 	log.Info("user record was created", "user_id", userID)
 
-	sessionID := session.CreateSession(userID)
+	sessionID, err := session.CreateSession(userID)
+	if err != nil {
+		return 500, err
+	}
 
-	log.Info("session was created", "session_id", sessionID)
+	log.Info("session was created")
 
 	http.SetCookie(w, &http.Cookie{
-		Name:    "session_id",
-		Value:   sessionID,
-		Domain:  os.Getenv("COOKIE_DOMAIN"),
-		Path:    "/",
-		Expires: time.Now().Add(24 * time.Hour),
+		Name:     "session_id",
+		Value:    sessionID,
+		Domain:   os.Getenv("COOKIE_DOMAIN"),
+		Path:     "/",
+		Expires:  time.Now().Add(24 * time.Hour),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
 	})
 
 	log.Info("successfully created user", "id", userID, "username", createUserRequest.Username)
@@ -124,17 +123,19 @@ func deleteExistingSession(w http.ResponseWriter, r *http.Request, session *sess
 		return err
 	}
 
-	_, err = session.GetSession(cookie.Value)
-	if err == nil {
-		session.DeleteSession(cookie.Value)
+	err = session.DeleteSession(cookie.Value)
+	if err != nil {
+		return err
 	}
 
 	deleteCookie := &http.Cookie{
-		Name:    "session_id",
-		Value:   "",
-		Path:    "/",
-		Domain:  os.Getenv("COOKIE_DOMAIN"),
-		Expires: time.Unix(0, 0),
+		Name:     "session_id",
+		Value:    "",
+		Path:     "/",
+		Domain:   os.Getenv("COOKIE_DOMAIN"),
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
 	}
 
 	http.SetCookie(w, deleteCookie)
@@ -142,7 +143,42 @@ func deleteExistingSession(w http.ResponseWriter, r *http.Request, session *sess
 	return nil
 }
 
-func createUserDBRecord(cur createUserRequest, hash string, admin bool, db *sqlx.DB) (userID int, err error) {
+// createUserDBRecord creates the user and its permission. The first user is
+// the admin, every later user must redeem a valid invite and receives the
+// permission set on it, even when no admin is left
+func createUserDBRecord(cur createUserRequest, hash string, db *sqlx.DB) (userID int, status int, err error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return 0, 500, err
+	}
+	defer tx.Rollback()
+
+	log.Info("transaction has started")
+
+	// checked inside the transaction so two first signups can't both become admin
+	userCount, err := countUsers(tx)
+	if err != nil {
+		return 0, 500, err
+	}
+
+	permission := types.Permission{Admin: true}
+	invite := types.Invite{}
+
+	if userCount > 0 {
+		if cur.Invite == "" {
+			return 0, 403, errInviteRequired
+		}
+
+		invite, err = getValidInvite(cur.Invite, tx)
+		if err != nil {
+			return 0, 403, err
+		}
+
+		permission = invite.Permission()
+	}
+
+	log.Info("permission is set to", "permission", permission)
+
 	createNewUserQuery := squirrel.
 		Insert("User").
 		Columns("Username", "Password").
@@ -151,37 +187,19 @@ func createUserDBRecord(cur createUserRequest, hash string, admin bool, db *sqlx
 
 	sql, args, err := createNewUserQuery.ToSql()
 	if err != nil {
-		return 0, err
+		return 0, 500, err
 	}
 
-	log.Info("create user query created", "query", sql, "args", args)
+	log.Info("create user query created", "query", sql)
 
 	user := types.User{}
 
-	tx, err := db.Beginx()
-	if err != nil {
-		return 0, err
-	}
-	defer tx.Rollback()
-
-	log.Info("transaction has started")
-
-	rows, err := tx.Queryx(sql, args...)
+	err = tx.QueryRowx(sql, args...).StructScan(&user)
 	if err != nil {
-		log.Error("failed to begin transaction", "err", err)
-		return 0, err
-	}
-	defer rows.Close()
-
-	log.Info("query was successful")
-
-	if rows.Next() {
-		err = rows.StructScan(&user)
-		if err != nil {
-			return 0, err
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return 0, 400, errors.New("that username is taken")
 		}
-	} else {
-		return 0, errors.New("error getting returned user")
+		return 0, 500, err
 	}
 
 	log.Info("user record", "user", user)
@@ -189,62 +207,73 @@ func createUserDBRecord(cur createUserRequest, hash string, admin bool, db *sqlx
 	createNewPermissionQuery := squirrel.
 		Insert("Permission").
 		Columns("UserID", "Admin", "ManageServices", "ManageTags", "ManageForwarding", "ViewLogs").
-		Values(user.ID, admin, false, false, false, false).
+		Values(
+			user.ID,
+			permission.Admin,
+			permission.ManageServices,
+			permission.ManageTags,
+			permission.ManageForwarding,
+			permission.ViewLogs,
+		).
 		Suffix("RETURNING ID")
 
 	sql, args, err = createNewPermissionQuery.ToSql()
 	if err != nil {
-		return 0, err
+		return 0, 500, err
 	}
 
 	log.Info("create permission query", "query", sql, "args", args)
 
-	permission := types.Permission{}
-
-	rows, err = tx.Queryx(sql, args...)
+	err = tx.QueryRowx(sql, args...).Scan(&permission.ID)
 	if err != nil {
-		return 0, err
-	}
-	defer rows.Close()
-
-	log.Info("permission request was successfully executed")
-
-	if rows.Next() {
-		err = rows.StructScan(&permission)
-		if err != nil {
-			return 0, err
-		}
-	} else {
-		return 0, errors.New("error getting returned permission")
+		return 0, 500, err
 	}
 
-	log.Info("permission was created", "permission", permission)
+	log.Info("permission was created", "permission_id", permission.ID)
 
 	updateUserPermissionQuery := squirrel.
 		Update("User").
 		Set("PermissionID", permission.ID).
-		Where("ID", user.ID)
+		Where(squirrel.Eq{"ID": user.ID})
 
 	sql, args, err = updateUserPermissionQuery.ToSql()
 	if err != nil {
-		return 0, err
+		return 0, 500, err
 	}
 
 	_, err = tx.Exec(sql, args...)
 	if err != nil {
-		return 0, err
+		return 0, 500, err
 	}
 
 	log.Info("user was updated", "permission_id", permission.ID)
 
+	if invite.ID != 0 {
+		err = redeemInvite(invite.ID, user.ID, tx)
+		if err != nil {
+			return 0, 403, err
+		}
+
+		log.Info("invite was redeemed", "invite_id", invite.ID)
+	}
+
 	err = tx.Commit()
 	if err != nil {
-		return 0, err
+		return 0, 500, err
 	}
 
 	log.Info("tx was committed")
 
-	return user.ID, nil
+	return user.ID, 200, nil
+}
+
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
 }
 
 func validateUsernameAndPassword(username string, password string) (status int, err error) {
@@ -256,6 +285,10 @@ func validateUsernameAndPassword(username string, password string) (status int,
 		return 400, errors.New("your username cannot be over 16 charactres")
 	}
 
+	return ValidatePassword(password)
+}
+
+func ValidatePassword(password string) (status int, err error) {
 	if len(password) > 74 {
 		return 400, errors.New("your password cannot be over 74 characters")
 	}
@@ -0,0 +1,110 @@
+package user
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ferretcode/pricetag/token"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+var errInvalidInvite = errors.New("this invite is invalid, expired or has already been used")
+
+// usersExist reports whether signing up needs an invite, only the very
+// first user may sign up without one
+func usersExist(db *sqlx.DB) (bool, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	count, err := countUsers(tx)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func countUsers(tx *sqlx.Tx) (int, error) {
+	countUsersQuery := squirrel.
+		Select("COUNT(*)").
+		From("User")
+
+	sql, args, err := countUsersQuery.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	err = tx.Get(&count, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func getValidInvite(inviteToken string, tx *sqlx.Tx) (types.Invite, error) {
+	selectInviteQuery := squirrel.
+		Select("*").
+		From("Invite").
+		Where(squirrel.Eq{"TokenHash": token.Hash(inviteToken)})
+
+	query, args, err := selectInviteQuery.ToSql()
+	if err != nil {
+		return types.Invite{}, err
+	}
+
+	invite := types.Invite{}
+
+	err = tx.Get(&invite, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return types.Invite{}, errInvalidInvite
+		}
+		return types.Invite{}, err
+	}
+
+	if invite.Used() || invite.Expired() {
+		return types.Invite{}, errInvalidInvite
+	}
+
+	return invite, nil
+}
+
+// redeemInvite marks the invite used. The UsedAt condition makes sure an
+// invite can only be redeemed once
+func redeemInvite(inviteID int, userID int, tx *sqlx.Tx) error {
+	redeemInviteQuery := squirrel.
+		Update("Invite").
+		Set("UsedBy", userID).
+		Set("UsedAt", time.Now().Unix()).
+		Where(squirrel.Eq{"ID": inviteID, "UsedAt": 0})
+
+	sql, args, err := redeemInviteQuery.ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return errInvalidInvite
+	}
+
+	return nil
+}
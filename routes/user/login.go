@@ -77,13 +77,19 @@ func Login(w http.ResponseWriter, r *http.Request, db *sqlx.DB, session *session
 		return 403, errors.New("the password is not correct")
 	}
 
-	sessionID := session.CreateSession(user.ID)
+	sessionID, err := session.CreateSession(user.ID)
+	if err != nil {
+		return 500, err
+	}
+
 	http.SetCookie(w, &http.Cookie{
-		Name:    "session_id",
-		Domain:  os.Getenv("COOKIE_DOMAIN"),
-		Path:    "/",
-		Value:   sessionID,
-		Expires: time.Now().Add(24 * time.Hour),
+		Name:     "session_id",
+		Domain:   os.Getenv("COOKIE_DOMAIN"),
+		Path:     "/",
+		Value:    sessionID,
+		Expires:  time.Now().Add(24 * time.Hour),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
 	})
 
 	http.Redirect(w, r, "/dashboard/home", http.StatusFound)
@@ -0,0 +1,18 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/ferretcode/pricetag/session"
+)
+
+func Logout(w http.ResponseWriter, r *http.Request, session *session.SessionManager) (status int, err error) {
+	err = deleteExistingSession(w, r, session)
+	if err != nil {
+		return 500, err
+	}
+
+	http.Redirect(w, r, "/user/login", http.StatusFound)
+
+	return 200, nil
+}
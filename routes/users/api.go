@@ -0,0 +1,123 @@
+package users
+
+import (
+	"net/http"
+
+	"github.com/ferretcode/pricetag/api"
+	"github.com/ferretcode/pricetag/session"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+type permissionRequest struct {
+	Admin            bool `json:"admin"`
+	ManageServices   bool `json:"manageServices"`
+	ManageTags       bool `json:"manageTags"`
+	ManageForwarding bool `json:"manageForwarding"`
+	ViewLogs         bool `json:"viewLogs"`
+}
+
+type passwordRequest struct {
+	Password string `json:"password"`
+}
+
+func APIList(w http.ResponseWriter, r *http.Request, db *sqlx.DB) (status int, err error) {
+	users, err := getUsers(db)
+	if err != nil {
+		return 500, err
+	}
+
+	err = api.WriteJSON(w, 200, users)
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
+
+// APIUpdatePermissions replaces every permission of the user, flags missing
+// from the body are revoked
+func APIUpdatePermissions(w http.ResponseWriter, r *http.Request, db *sqlx.DB) (status int, err error) {
+	id, err := getUserID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	permissionRequest := permissionRequest{}
+
+	err = api.DecodeJSON(r, &permissionRequest)
+	if err != nil {
+		return 400, err
+	}
+
+	permission := types.Permission{
+		Admin:            permissionRequest.Admin,
+		ManageServices:   permissionRequest.ManageServices,
+		ManageTags:       permissionRequest.ManageTags,
+		ManageForwarding: permissionRequest.ManageForwarding,
+		ViewLogs:         permissionRequest.ViewLogs,
+	}
+
+	currentUser := r.Context().Value("user").(types.User)
+
+	status, err = updatePermissions(id, permission, currentUser, db)
+	if err != nil {
+		return status, err
+	}
+
+	err = api.WriteJSON(w, 204, nil)
+	if err != nil {
+		return 500, err
+	}
+
+	return 204, nil
+}
+
+func APIResetPassword(w http.ResponseWriter, r *http.Request, db *sqlx.DB, session *session.SessionManager) (status int, err error) {
+	id, err := getUserID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	passwordRequest := passwordRequest{}
+
+	err = api.DecodeJSON(r, &passwordRequest)
+	if err != nil {
+		return 400, err
+	}
+
+	currentUser := r.Context().Value("user").(types.User)
+
+	status, err = resetPassword(id, passwordRequest.Password, currentUser, db, session)
+	if err != nil {
+		return status, err
+	}
+
+	err = api.WriteJSON(w, 204, nil)
+	if err != nil {
+		return 500, err
+	}
+
+	return 204, nil
+}
+
+func APIDelete(w http.ResponseWriter, r *http.Request, db *sqlx.DB, session *session.SessionManager) (status int, err error) {
+	id, err := getUserID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	currentUser := r.Context().Value("user").(types.User)
+
+	status, err = deleteUser(id, currentUser, db, session)
+	if err != nil {
+		return status, err
+	}
+
+	err = api.WriteJSON(w, 204, nil)
+	if err != nil {
+		return 500, err
+	}
+
+	return 204, nil
+}
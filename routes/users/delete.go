@@ -0,0 +1,118 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/session"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+func Delete(w http.ResponseWriter, r *http.Request, db *sqlx.DB, session *session.SessionManager) (status int, err error) {
+	id, err := getUserID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	currentUser := r.Context().Value("user").(types.User)
+
+	status, err = deleteUser(id, currentUser, db, session)
+	if err != nil {
+		return status, err
+	}
+
+	http.Redirect(w, r, "/dashboard/users", http.StatusFound)
+
+	return 200, nil
+}
+
+func deleteUser(id int, currentUser types.User, db *sqlx.DB, session *session.SessionManager) (status int, err error) {
+	if id == currentUser.ID {
+		return 400, errors.New("you cannot delete your own account")
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return 500, err
+	}
+	defer tx.Rollback()
+
+	admins, err := countOtherAdmins(id, tx)
+	if err != nil {
+		return 500, err
+	}
+
+	if admins == 0 {
+		return 400, errors.New("there must be at least one admin")
+	}
+
+	// foreign keys aren't enforced by sqlite unless enabled, so the
+	// permission and api token rows are removed by hand
+	deleteTokensQuery := squirrel.
+		Delete("APIToken").
+		Where(squirrel.Eq{"UserID": id})
+
+	sql, args, err := deleteTokensQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	deletePermissionQuery := squirrel.
+		Delete("Permission").
+		Where(squirrel.Eq{"UserID": id})
+
+	sql, args, err = deletePermissionQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	_, err = tx.Exec(sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	deleteUserQuery := squirrel.
+		Delete("User").
+		Where(squirrel.Eq{"ID": id})
+
+	sql, args, err = deleteUserQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	res, err := tx.Exec(sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 500, err
+	}
+
+	if affected == 0 {
+		return 404, errUserNotFound
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 500, err
+	}
+
+	err = session.DeleteUserSessions(id)
+	if err != nil {
+		return 500, err
+	}
+
+	log.Info("user was deleted", "user_id", id, "by", currentUser.ID)
+
+	return 200, nil
+}
@@ -0,0 +1,33 @@
+package users
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+type usersData struct {
+	User       types.User
+	Permission types.Permission
+	Users      []userRow
+}
+
+func RenderUsersPage(w http.ResponseWriter, r *http.Request, db *sqlx.DB, templates *template.Template) (status int, err error) {
+	users, err := getUsers(db)
+	if err != nil {
+		return 500, err
+	}
+
+	err = templates.ExecuteTemplate(w, "users.html", usersData{
+		User:       r.Context().Value("user").(types.User),
+		Permission: r.Context().Value("permission").(types.Permission),
+		Users:      users,
+	})
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
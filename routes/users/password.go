@@ -0,0 +1,83 @@
+package users
+
+import (
+	"net/http"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/routes/user"
+	"github.com/ferretcode/pricetag/session"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+func ResetPassword(w http.ResponseWriter, r *http.Request, db *sqlx.DB, session *session.SessionManager) (status int, err error) {
+	id, err := getUserID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		return 500, err
+	}
+
+	password := r.PostFormValue("password")
+
+	currentUser := r.Context().Value("user").(types.User)
+
+	status, err = resetPassword(id, password, currentUser, db, session)
+	if err != nil {
+		return status, err
+	}
+
+	http.Redirect(w, r, "/dashboard/users", http.StatusFound)
+
+	return 200, nil
+}
+
+func resetPassword(id int, password string, currentUser types.User, db *sqlx.DB, session *session.SessionManager) (status int, err error) {
+	status, err = user.ValidatePassword(password)
+	if err != nil {
+		return status, err
+	}
+
+	hash, err := user.HashPassword(password)
+	if err != nil {
+		return 500, err
+	}
+
+	updatePasswordQuery := squirrel.
+		Update("User").
+		Set("Password", hash).
+		Where(squirrel.Eq{"ID": id})
+
+	sql, args, err := updatePasswordQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	res, err := db.Exec(sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 500, err
+	}
+
+	if affected == 0 {
+		return 404, errUserNotFound
+	}
+
+	// the old password may be compromised, so every session of the user ends
+	err = session.DeleteUserSessions(id)
+	if err != nil {
+		return 500, err
+	}
+
+	log.Info("user password was reset", "user_id", id, "by", currentUser.ID)
+
+	return 200, nil
+}
@@ -0,0 +1,103 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/jmoiron/sqlx"
+)
+
+func UpdatePermissions(w http.ResponseWriter, r *http.Request, db *sqlx.DB) (status int, err error) {
+	id, err := getUserID(r)
+	if err != nil {
+		return 400, err
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		return 500, err
+	}
+
+	permission := types.Permission{
+		Admin:            r.PostFormValue("admin") == "on",
+		ManageServices:   r.PostFormValue("manage_services") == "on",
+		ManageTags:       r.PostFormValue("manage_tags") == "on",
+		ManageForwarding: r.PostFormValue("manage_forwarding") == "on",
+		ViewLogs:         r.PostFormValue("view_logs") == "on",
+	}
+
+	currentUser := r.Context().Value("user").(types.User)
+
+	status, err = updatePermissions(id, permission, currentUser, db)
+	if err != nil {
+		return status, err
+	}
+
+	http.Redirect(w, r, "/dashboard/users", http.StatusFound)
+
+	return 200, nil
+}
+
+// updatePermissions refuses to leave the instance without an admin
+func updatePermissions(id int, permission types.Permission, currentUser types.User, db *sqlx.DB) (status int, err error) {
+	if id == currentUser.ID && !permission.Admin {
+		return 400, errors.New("you cannot remove your own admin permission")
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return 500, err
+	}
+	defer tx.Rollback()
+
+	if !permission.Admin {
+		admins, err := countOtherAdmins(id, tx)
+		if err != nil {
+			return 500, err
+		}
+
+		if admins == 0 {
+			return 400, errors.New("there must be at least one admin")
+		}
+	}
+
+	updatePermissionQuery := squirrel.
+		Update("Permission").
+		Set("Admin", permission.Admin).
+		Set("ManageServices", permission.ManageServices).
+		Set("ManageTags", permission.ManageTags).
+		Set("ManageForwarding", permission.ManageForwarding).
+		Set("ViewLogs", permission.ViewLogs).
+		Where(squirrel.Eq{"UserID": id})
+
+	sql, args, err := updatePermissionQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	res, err := tx.Exec(sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 500, err
+	}
+
+	if affected == 0 {
+		return 404, errUserNotFound
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 500, err
+	}
+
+	log.Info("user permissions were updated", "user_id", id, "by", currentUser.ID)
+
+	return 200, nil
+}
@@ -0,0 +1,105 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ferretcode/pricetag/types"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+)
+
+var errUserNotFound = errors.New("user not found")
+
+type userRow struct {
+	User       types.User       `json:"user"`
+	Permission types.Permission `json:"permission"`
+}
+
+func getUserID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		return 0, errors.New("invalid user id")
+	}
+
+	return id, nil
+}
+
+func getUsers(db *sqlx.DB) ([]userRow, error) {
+	selectUsersQuery := squirrel.
+		Select("*").
+		From("User").
+		OrderBy("Username")
+
+	sql, args, err := selectUsersQuery.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	users := []types.User{}
+
+	err = db.Select(&users, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	selectPermissionsQuery := squirrel.
+		Select("*").
+		From("Permission")
+
+	sql, args, err = selectPermissionsQuery.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := []types.Permission{}
+
+	err = db.Select(&permissions, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionsByUser := make(map[int]types.Permission, len(permissions))
+	for _, permission := range permissions {
+		permissionsByUser[permission.UserID] = permission
+	}
+
+	rows := make([]userRow, len(users))
+	for i := range users {
+		// never hand password hashes to templates
+		users[i].Password = ""
+
+		rows[i] = userRow{
+			User:       users[i],
+			Permission: permissionsByUser[users[i].ID],
+		}
+	}
+
+	return rows, nil
+}
+
+// countOtherAdmins counts admins other than userID, so the last admin can't
+// be demoted or deleted
+func countOtherAdmins(userID int, tx *sqlx.Tx) (int, error) {
+	countAdminsQuery := squirrel.
+		Select("COUNT(*)").
+		From("Permission").
+		Where(squirrel.Eq{"Admin": true}).
+		Where(squirrel.NotEq{"UserID": userID})
+
+	sql, args, err := countAdminsQuery.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	err = tx.Get(&count, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -1,23 +1,41 @@
 package session
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
-	"sync"
 	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found please log in")
+	ErrSessionExpired  = errors.New("session expired")
 )
 
+// sessionIDBytes is the amount of randomness in a session id, 256 bits
+const sessionIDBytes = 32
+
+// touchInterval limits how often a session's expiry is pushed back, so that
+// persistent stores aren't written on every request
+const touchInterval = time.Minute
+
 type Session struct {
 	UserID    int
 	ExpiresAt time.Time
 }
 
 type SessionManager struct {
-	sessions   sync.Map
+	store      SessionStore
 	expiration time.Duration
 }
 
-func NewSessionManager(expiration time.Duration) *SessionManager {
+// NewSessionManager creates a manager whose sessions expire after being idle
+// for the expiration duration
+func NewSessionManager(store SessionStore, expiration time.Duration) *SessionManager {
 	manager := &SessionManager{
+		store:      store,
 		expiration: expiration,
 	}
 
@@ -25,32 +43,66 @@ func NewSessionManager(expiration time.Duration) *SessionManager {
 	return manager
 }
 
-func (sm *SessionManager) CreateSession(userID int) string {
-	sessionID := generateSessionID()
-	sm.sessions.Store(sessionID, Session{
+func (sm *SessionManager) CreateSession(userID int) (string, error) {
+	sessionID, err := generateSessionID()
+	if err != nil {
+		return "", err
+	}
+
+	err = sm.store.Create(sessionID, Session{
 		UserID:    userID,
 		ExpiresAt: time.Now().Add(sm.expiration),
 	})
-	return sessionID
+	if err != nil {
+		return "", err
+	}
+
+	return sessionID, nil
 }
 
+// GetSession returns a valid session and slides its expiry forward
 func (sm *SessionManager) GetSession(sessionID string) (Session, error) {
-	value, ok := sm.sessions.Load(sessionID)
+	session, ok, err := sm.store.Get(sessionID)
+	if err != nil {
+		return Session{}, err
+	}
+
 	if !ok {
-		return Session{}, errors.New("session not found please log in")
+		return Session{}, ErrSessionNotFound
 	}
 
-	session := value.(Session)
-	if time.Now().After(session.ExpiresAt) {
-		sm.sessions.Delete(sessionID)
-		return Session{}, errors.New("session expired")
+	now := time.Now()
+
+	if now.After(session.ExpiresAt) {
+		err = sm.store.Delete(sessionID)
+		if err != nil {
+			return Session{}, err
+		}
+
+		return Session{}, ErrSessionExpired
+	}
+
+	expiresAt := now.Add(sm.expiration)
+
+	if expiresAt.Sub(session.ExpiresAt) >= touchInterval {
+		err = sm.store.Touch(sessionID, expiresAt)
+		if err != nil {
+			return Session{}, err
+		}
+
+		session.ExpiresAt = expiresAt
 	}
 
 	return session, nil
 }
 
-func (sm *SessionManager) DeleteSession(sessionID string) {
-	sm.sessions.Delete(sessionID)
+func (sm *SessionManager) DeleteSession(sessionID string) error {
+	return sm.store.Delete(sessionID)
+}
+
+// DeleteUserSessions logs a user out everywhere
+func (sm *SessionManager) DeleteUserSessions(userID int) error {
+	return sm.store.DeleteUserSessions(userID)
 }
 
 func (sm *SessionManager) cleanupExpiredSessions() {
@@ -58,16 +110,20 @@ func (sm *SessionManager) cleanupExpiredSessions() {
 	defer ticker.Stop()
 
 	for range ticker.C {
-		sm.sessions.Range(func(key, value any) bool {
-			session := value.(Session)
-			if time.Now().After(session.ExpiresAt) {
-				sm.sessions.Delete(key)
-			}
-			return true
-		})
+		err := sm.store.DeleteExpired(time.Now())
+		if err != nil {
+			log.Error("error cleaning up expired sessions", "err", err)
+		}
 	}
 }
 
-func generateSessionID() string {
-	return time.Now().Format("20060102150405.000000")
+func generateSessionID() (string, error) {
+	b := make([]byte, sessionIDBytes)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
 }
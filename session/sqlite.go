@@ -0,0 +1,104 @@
+package session
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// SQLiteStore keeps sessions in the Session table so they survive restarts
+type SQLiteStore struct {
+	db *sqlx.DB
+}
+
+type sessionRow struct {
+	UserID    int   `db:"UserID"`
+	ExpiresAt int64 `db:"ExpiresAt"`
+}
+
+func NewSQLiteStore(db *sqlx.DB) *SQLiteStore {
+	return &SQLiteStore{
+		db: db,
+	}
+}
+
+func (ss *SQLiteStore) Create(sessionID string, session Session) error {
+	createSessionQuery := squirrel.
+		Insert("Session").
+		Columns("ID", "UserID", "ExpiresAt").
+		Values(sessionID, session.UserID, session.ExpiresAt.Unix())
+
+	return ss.exec(createSessionQuery)
+}
+
+func (ss *SQLiteStore) Get(sessionID string) (Session, bool, error) {
+	selectSessionQuery := squirrel.
+		Select("UserID", "ExpiresAt").
+		From("Session").
+		Where(squirrel.Eq{"ID": sessionID})
+
+	query, args, err := selectSessionQuery.ToSql()
+	if err != nil {
+		return Session{}, false, err
+	}
+
+	row := sessionRow{}
+
+	err = ss.db.Get(&row, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Session{}, false, nil
+		}
+		return Session{}, false, err
+	}
+
+	return Session{
+		UserID:    row.UserID,
+		ExpiresAt: time.Unix(row.ExpiresAt, 0),
+	}, true, nil
+}
+
+func (ss *SQLiteStore) Touch(sessionID string, expiresAt time.Time) error {
+	touchSessionQuery := squirrel.
+		Update("Session").
+		Set("ExpiresAt", expiresAt.Unix()).
+		Where(squirrel.Eq{"ID": sessionID})
+
+	return ss.exec(touchSessionQuery)
+}
+
+func (ss *SQLiteStore) Delete(sessionID string) error {
+	deleteSessionQuery := squirrel.
+		Delete("Session").
+		Where(squirrel.Eq{"ID": sessionID})
+
+	return ss.exec(deleteSessionQuery)
+}
+
+func (ss *SQLiteStore) DeleteExpired(now time.Time) error {
+	deleteExpiredQuery := squirrel.
+		Delete("Session").
+		Where(squirrel.Lt{"ExpiresAt": now.Unix()})
+
+	return ss.exec(deleteExpiredQuery)
+}
+
+func (ss *SQLiteStore) DeleteUserSessions(userID int) error {
+	deleteUserSessionsQuery := squirrel.
+		Delete("Session").
+		Where(squirrel.Eq{"UserID": userID})
+
+	return ss.exec(deleteUserSessionsQuery)
+}
+
+func (ss *SQLiteStore) exec(builder squirrel.Sqlizer) error {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = ss.db.Exec(query, args...)
+	return err
+}
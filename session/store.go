@@ -0,0 +1,81 @@
+package session
+
+import (
+	"sync"
+	"time"
+)
+
+// SessionStore persists sessions by id
+type SessionStore interface {
+	Create(sessionID string, session Session) error
+	Get(sessionID string) (session Session, ok bool, err error)
+	Touch(sessionID string, expiresAt time.Time) error
+	Delete(sessionID string) error
+	DeleteExpired(now time.Time) error
+	DeleteUserSessions(userID int) error
+}
+
+// MemoryStore keeps sessions in memory, they are lost when the process exits
+type MemoryStore struct {
+	sessions sync.Map
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (ms *MemoryStore) Create(sessionID string, session Session) error {
+	ms.sessions.Store(sessionID, session)
+	return nil
+}
+
+func (ms *MemoryStore) Get(sessionID string) (Session, bool, error) {
+	value, ok := ms.sessions.Load(sessionID)
+	if !ok {
+		return Session{}, false, nil
+	}
+
+	return value.(Session), true, nil
+}
+
+func (ms *MemoryStore) Touch(sessionID string, expiresAt time.Time) error {
+	value, ok := ms.sessions.Load(sessionID)
+	if !ok {
+		return nil
+	}
+
+	session := value.(Session)
+	session.ExpiresAt = expiresAt
+
+	ms.sessions.Store(sessionID, session)
+	return nil
+}
+
+func (ms *MemoryStore) Delete(sessionID string) error {
+	ms.sessions.Delete(sessionID)
+	return nil
+}
+
+func (ms *MemoryStore) DeleteUserSessions(userID int) error {
+	ms.sessions.Range(func(key, value any) bool {
+		session := value.(Session)
+		if session.UserID == userID {
+			ms.sessions.Delete(key)
+		}
+		return true
+	})
+
+	return nil
+}
+
+func (ms *MemoryStore) DeleteExpired(now time.Time) error {
+	ms.sessions.Range(func(key, value any) bool {
+		session := value.(Session)
+		if now.After(session.ExpiresAt) {
+			ms.sessions.Delete(key)
+		}
+		return true
+	})
+
+	return nil
+}
@@ -1,9 +1,52 @@
 package sink
 
 import (
+	"context"
+	"sync"
+
 	"github.com/ferretcode/pricetag/types"
 )
 
+// Handler is called with every batch of logs that passes through the sink.
+// Handlers run sequentially on the sink goroutine so they must not block
+type Handler func(logs []types.Log)
+
 type Sink struct {
 	NewLog chan []types.Log
+
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewSink(buffer int) *Sink {
+	return &Sink{
+		NewLog: make(chan []types.Log, buffer),
+	}
+}
+
+// Handle registers a handler. Handlers are called in registration order, so
+// a handler may rely on changes made to the batch by earlier handlers
+func (s *Sink) Handle(handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers = append(s.handlers, handler)
+}
+
+// Run drains NewLog until the context is cancelled
+func (s *Sink) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case logs := <-s.NewLog:
+			s.mu.RLock()
+			handlers := s.handlers
+			s.mu.RUnlock()
+
+			for _, handler := range handlers {
+				handler(logs)
+			}
+		}
+	}
 }
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/matcher"
+	"github.com/ferretcode/pricetag/routes/services"
+	"github.com/ferretcode/pricetag/sink"
+	"github.com/ferretcode/pricetag/sources"
+	"github.com/ferretcode/pricetag/sources/file"
+	"github.com/ferretcode/pricetag/sources/otlp"
+	"github.com/ferretcode/pricetag/sources/railway"
+	"github.com/ferretcode/pricetag/sources/syslog"
+	"github.com/jmoiron/sqlx"
+)
+
+type sourceBuilder func(db *sqlx.DB, tagMatcher *matcher.Matcher) (sources.Source, error)
+
+// sourceBuilders maps the names accepted in SOURCES to their constructor
+var sourceBuilders = map[string]sourceBuilder{
+	"railway": newRailwaySource,
+	"file":    newFileSource,
+	"syslog":  newSyslogSource,
+	"otlp":    newOTLPSource,
+}
+
+// buildSources creates every source enabled by SOURCES. A source that is
+// missing its configuration is skipped with a warning so the dashboard still
+// starts
+func buildSources(db *sqlx.DB, tagMatcher *matcher.Matcher) ([]sources.Source, error) {
+	active := []sources.Source{}
+
+	for _, name := range sources.Enabled() {
+		build, ok := sourceBuilders[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown source %q", name)
+		}
+
+		source, err := build(db, tagMatcher)
+		if err != nil {
+			log.Warn("source is disabled", "source", name, "err", err)
+			continue
+		}
+
+		active = append(active, source)
+	}
+
+	return active, nil
+}
+
+// startSources runs every source under a supervisor, feeding the sink
+func startSources(ctx context.Context, wg *sync.WaitGroup, active []sources.Source, logSink *sink.Sink) {
+	for _, source := range active {
+		go func() {
+			healthCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+
+			if err := source.HealthCheck(healthCtx); err != nil {
+				log.Warn("source health check failed", "source", source.Name(), "err", err)
+			}
+		}()
+
+		supervise(ctx, wg, source.Name(), func(ctx context.Context) error {
+			return source.Start(ctx, logSink)
+		})
+	}
+}
+
+// findRailway returns the client and config of the railway source, which the
+// services pages need. Both are nil when railway isn't active
+func findRailway(active []sources.Source) (*railway.GraphQLConfig, *railway.Config) {
+	for _, source := range active {
+		if railwaySource, ok := source.(*railway.Source); ok {
+			return railwaySource.Client(), railwaySource.Config()
+		}
+	}
+
+	return nil, nil
+}
+
+func newRailwaySource(db *sqlx.DB, tagMatcher *matcher.Matcher) (sources.Source, error) {
+	serviceIds, err := services.GetTrackedServiceIds(db)
+	if err != nil {
+		return nil, err
+	}
+
+	environments, err := services.GetEnvironments(db)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := railway.GenerateConfig(serviceIds, environments)
+	if err != nil {
+		return nil, err
+	}
+
+	gql, err := railway.NewClient(&railway.GraphQLConfig{
+		AuthToken:           config.ApiKey,
+		BaseURL:             railway.BaseURL,
+		BaseSubscriptionURL: railway.BaseSubscriptionURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(environments) == 0 {
+		importRailwayEnvironment(db, gql, config)
+	}
+
+	return railway.NewSource(gql, config, railway.NewCursorStore(db), tagMatcher), nil
+}
+
+// importRailwayEnvironment adds RAILWAY_ENVIRONMENT_ID, which configured the
+// one streamed environment before they moved to the database
+func importRailwayEnvironment(db *sqlx.DB, gql *railway.GraphQLConfig, config *railway.Config) {
+	environmentID := os.Getenv("RAILWAY_ENVIRONMENT_ID")
+	if environmentID == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, _, err := services.CreateEnvironment(ctx, environmentID, db, gql, config)
+	if err != nil {
+		log.Warn("could not import RAILWAY_ENVIRONMENT_ID, add it on the services page", "err", err)
+	}
+}
+
+func newFileSource(db *sqlx.DB, tagMatcher *matcher.Matcher) (sources.Source, error) {
+	config, err := file.GenerateConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return file.NewSource(config), nil
+}
+
+func newSyslogSource(db *sqlx.DB, tagMatcher *matcher.Matcher) (sources.Source, error) {
+	config, err := syslog.GenerateConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return syslog.NewSource(config), nil
+}
+
+func newOTLPSource(db *sqlx.DB, tagMatcher *matcher.Matcher) (sources.Source, error) {
+	config, err := otlp.GenerateConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return otlp.NewSource(config, db), nil
+}
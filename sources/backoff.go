@@ -0,0 +1,43 @@
+package sources
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff computes reconnect delays that double from Min up to Max. Each
+// delay is randomized between half and all of its value, so sources that
+// dropped together don't reconnect together
+type Backoff struct {
+	Min time.Duration
+	Max time.Duration
+
+	attempts int
+}
+
+// Next returns the delay before the next attempt
+func (b *Backoff) Next() time.Duration {
+	delay := b.Max
+
+	// past 32 doublings the shift overflows, and any sane Max is reached
+	// long before
+	if b.attempts < 32 && b.Min<<b.attempts < b.Max {
+		delay = b.Min << b.attempts
+	}
+
+	b.attempts++
+
+	half := delay / 2
+
+	return half + rand.N(half+1)
+}
+
+// Attempts returns how many delays were handed out since the last reset
+func (b *Backoff) Attempts() int {
+	return b.attempts
+}
+
+// Reset starts over from Min, once a connection proved healthy
+func (b *Backoff) Reset() {
+	b.attempts = 0
+}
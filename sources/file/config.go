@@ -0,0 +1,37 @@
+package file
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// StdinPath is the path that reads stdin instead of a file
+const StdinPath = "-"
+
+type Config struct {
+	Paths []string
+	// FromStart reads files from the beginning instead of only new lines
+	FromStart bool
+}
+
+// GenerateConfig reads FILE_SOURCE_PATHS, a comma separated list of files,
+// and FILE_SOURCE_FROM_START
+func GenerateConfig() (*Config, error) {
+	config := Config{}
+
+	for _, path := range strings.Split(os.Getenv("FILE_SOURCE_PATHS"), ",") {
+		path = strings.TrimSpace(path)
+		if path != "" {
+			config.Paths = append(config.Paths, path)
+		}
+	}
+
+	if len(config.Paths) == 0 {
+		return nil, errors.New("file source paths must be present")
+	}
+
+	config.FromStart = os.Getenv("FILE_SOURCE_FROM_START") == "true"
+
+	return &config, nil
+}
@@ -0,0 +1,138 @@
+// Package file tails local files, or stdin, into the sink. Each line is
+// parsed as a json object when possible and as plain text otherwise
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ferretcode/pricetag/sink"
+	"github.com/ferretcode/pricetag/sources"
+	"github.com/ferretcode/pricetag/types"
+)
+
+// maxBatch caps the lines sent to the sink at once
+const maxBatch = 500
+
+type Source struct {
+	tailers []*tailer
+	stdin   *stdinReader
+}
+
+func NewSource(config *Config) *Source {
+	source := &Source{}
+
+	for _, path := range config.Paths {
+		if path == StdinPath {
+			source.stdin = &stdinReader{input: os.Stdin}
+			continue
+		}
+
+		source.tailers = append(source.tailers, &tailer{
+			path:      path,
+			fromStart: config.FromStart,
+		})
+	}
+
+	return source
+}
+
+func (s *Source) Name() string {
+	return "file"
+}
+
+// Start tails every path until the context is cancelled or one of them
+// fails. Read positions survive a restart, so a restarted source resumes
+// where it stopped
+func (s *Source) Start(ctx context.Context, logSink *sink.Sink) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, len(s.tailers)+1)
+	wg := &sync.WaitGroup{}
+
+	run := func(fn func(context.Context, *sink.Sink) error) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := fn(ctx, logSink); err != nil && ctx.Err() == nil {
+				errs <- err
+				cancel()
+			}
+		}()
+	}
+
+	for _, tailer := range s.tailers {
+		run(tailer.run)
+	}
+
+	if s.stdin != nil {
+		run(s.stdin.run)
+	}
+
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return ctx.Err()
+	}
+}
+
+// HealthCheck checks every file exists and can be read
+func (s *Source) HealthCheck(ctx context.Context) error {
+	for _, tailer := range s.tailers {
+		file, err := os.Open(tailer.path)
+		if err != nil {
+			return err
+		}
+		file.Close()
+	}
+
+	return nil
+}
+
+// toLogs parses lines read from path
+func toLogs(lines [][]byte, path string) []types.Log {
+	serviceID := "file:" + path
+	serviceName := filepath.Base(path)
+
+	if path == StdinPath {
+		serviceID = "stdin"
+		serviceName = "stdin"
+	}
+
+	logs := make([]types.Log, 0, len(lines))
+
+	for _, line := range lines {
+		log := sources.ParseLine(line, sources.DefaultKeys)
+		if log.Message == "" && len(log.Attributes) == 0 {
+			continue
+		}
+
+		log.ServiceID = serviceID
+		log.ServiceName = serviceName
+
+		logs = append(logs, log)
+	}
+
+	return logs
+}
+
+func send(ctx context.Context, logSink *sink.Sink, logs []types.Log) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	select {
+	case logSink.NewLog <- logs:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,79 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync"
+
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/sink"
+)
+
+// stdinReader reads stdin on a single goroutine for the life of the process,
+// since a blocked read can't be cancelled and stdin can't be reopened
+type stdinReader struct {
+	// input is os.Stdin outside of tests
+	input io.Reader
+	once  sync.Once
+	lines chan []byte
+}
+
+func (s *stdinReader) start() {
+	s.lines = make(chan []byte, maxBatch)
+
+	go func() {
+		defer close(s.lines)
+
+		reader := bufio.NewReaderSize(s.input, 64*1024)
+
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				s.lines <- line
+			}
+
+			if err != nil {
+				log.Info("stdin was closed", "err", err)
+				return
+			}
+		}
+	}()
+}
+
+func (s *stdinReader) run(ctx context.Context, logSink *sink.Sink) error {
+	s.once.Do(s.start)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case line, ok := <-s.lines:
+			if !ok {
+				// nothing more will arrive, wait for shutdown rather than
+				// being restarted in a loop
+				<-ctx.Done()
+				return ctx.Err()
+			}
+
+			lines := [][]byte{line}
+
+		drain:
+			for len(lines) < maxBatch {
+				select {
+				case line, ok := <-s.lines:
+					if !ok {
+						break drain
+					}
+					lines = append(lines, line)
+				default:
+					break drain
+				}
+			}
+
+			if err := send(ctx, logSink, toLogs(lines, StdinPath)); err != nil {
+				return err
+			}
+		}
+	}
+}
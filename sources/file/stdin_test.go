@@ -0,0 +1,66 @@
+package file
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ferretcode/pricetag/sink"
+)
+
+func TestStdinReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "lines",
+			input: "first\nsecond\n",
+			want:  []string{"first", "second"},
+		},
+		{
+			name:  "partial final line",
+			input: "first\nlast",
+			want:  []string{"first", "last"},
+		},
+		{
+			name:  "json and blank lines",
+			input: "{\"message\": \"from json\", \"level\": \"error\"}\n\nplain\n",
+			want:  []string{"from json", "plain"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			logSink := sink.NewSink(100)
+			reader := &stdinReader{input: strings.NewReader(test.input)}
+
+			done := make(chan error, 1)
+			go func() {
+				done <- reader.run(ctx, logSink)
+			}()
+
+			if got := receive(t, logSink, len(test.want)); !slices.Equal(got, test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+
+			// a closed stdin leaves run waiting for shutdown
+			select {
+			case err := <-done:
+				t.Fatalf("run returned %v before shutdown", err)
+			default:
+			}
+
+			cancel()
+
+			if err := <-done; err != context.Canceled {
+				t.Fatalf("run returned %v, want context.Canceled", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/sink"
+)
+
+const (
+	pollInterval = 250 * time.Millisecond
+	// maxLineLength flushes a line that never ends rather than buffering it
+	// forever
+	maxLineLength = 1 << 20
+)
+
+// tailer follows one file. Rotation is detected by the path pointing at a
+// different file, truncation by the file shrinking below the read offset
+type tailer struct {
+	path      string
+	fromStart bool
+
+	file    *os.File
+	reader  *bufio.Reader
+	info    os.FileInfo
+	offset  int64
+	partial []byte
+}
+
+func (t *tailer) run(ctx context.Context, logSink *sink.Sink) error {
+	defer t.close()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if t.file == nil {
+			err := t.open()
+			if err != nil {
+				if !errors.Is(err, fs.ErrNotExist) {
+					return err
+				}
+
+				// everything in a file created after we started is new
+				t.fromStart = true
+			}
+		}
+
+		if t.file != nil {
+			lines, err := t.readLines()
+			if err != nil {
+				return err
+			}
+
+			if len(lines) > 0 {
+				if err := send(ctx, logSink, toLogs(lines, t.path)); err != nil {
+					return err
+				}
+
+				// there may be more to read, poll again straight away
+				if len(lines) == maxBatch {
+					continue
+				}
+			}
+
+			rotated, err := t.checkRotation()
+			if err != nil {
+				return err
+			}
+
+			if rotated {
+				// lines may have been written between reaching the end and
+				// the rotation, read them before letting go of the old file
+				if err := t.drain(ctx, logSink); err != nil {
+					return err
+				}
+
+				t.close()
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// open opens the path, resuming at the saved offset when it is still the
+// same file. A file seen for the first time is read from its end unless
+// fromStart is set, a replacement file is read from the start
+func (t *tailer) open() error {
+	file, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	offset := int64(0)
+
+	switch {
+	case t.info != nil && os.SameFile(t.info, info) && info.Size() >= t.offset:
+		offset = t.offset
+	case t.info == nil && !t.fromStart:
+		offset = info.Size()
+	default:
+		t.partial = nil
+	}
+
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	if t.info != nil && !os.SameFile(t.info, info) {
+		log.Info("file was rotated", "path", t.path)
+	}
+
+	t.file = file
+	t.reader = bufio.NewReader(file)
+	t.info = info
+	t.offset = offset
+
+	return nil
+}
+
+// readLines reads up to maxBatch complete lines. An unterminated last line
+// is kept until the rest of it is written
+func (t *tailer) readLines() ([][]byte, error) {
+	lines := [][]byte{}
+
+	for len(lines) < maxBatch {
+		chunk, err := t.reader.ReadBytes('\n')
+		t.offset += int64(len(chunk))
+
+		if err != nil {
+			if err != io.EOF {
+				return nil, err
+			}
+
+			t.partial = append(t.partial, chunk...)
+
+			if len(t.partial) > maxLineLength {
+				lines = append(lines, t.partial)
+				t.partial = nil
+			}
+
+			break
+		}
+
+		line := chunk
+		if len(t.partial) > 0 {
+			line = append(t.partial, chunk...)
+			t.partial = nil
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// drain sends everything left in the current file
+func (t *tailer) drain(ctx context.Context, logSink *sink.Sink) error {
+	for {
+		lines, err := t.readLines()
+		if err != nil {
+			return err
+		}
+
+		if err := send(ctx, logSink, toLogs(lines, t.path)); err != nil {
+			return err
+		}
+
+		if len(lines) < maxBatch {
+			return nil
+		}
+	}
+}
+
+// checkRotation reports whether the path points at another file, or at no
+// file when it was moved away and not recreated yet. A truncated file is
+// read again from the start
+func (t *tailer) checkRotation() (rotated bool, err error) {
+	info, err := os.Stat(t.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	if !os.SameFile(t.info, info) {
+		return true, nil
+	}
+
+	if info.Size() < t.offset {
+		log.Info("file was truncated", "path", t.path)
+
+		_, err = t.file.Seek(0, io.SeekStart)
+		if err != nil {
+			return false, err
+		}
+
+		t.reader.Reset(t.file)
+		t.offset = 0
+		t.partial = nil
+	}
+
+	return false, nil
+}
+
+func (t *tailer) close() {
+	if t.file != nil {
+		t.file.Close()
+		t.file = nil
+		t.reader = nil
+	}
+}
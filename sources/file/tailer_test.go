@@ -0,0 +1,197 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ferretcode/pricetag/sink"
+)
+
+// waitTimeout bounds how long a test waits for lines the tailer should send
+const waitTimeout = 5 * time.Second
+
+// step changes the tailed file, then waits for want to be sent. A step
+// without want checks that nothing is sent for a few polls
+type step struct {
+	action func(t *testing.T, path string)
+	want   []string
+}
+
+func writeFile(content string) func(t *testing.T, path string) {
+	return func(t *testing.T, path string) {
+		t.Helper()
+
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("error writing the file: %v", err)
+		}
+	}
+}
+
+func appendFile(content string) func(t *testing.T, path string) {
+	return func(t *testing.T, path string) {
+		t.Helper()
+
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatalf("error opening the file: %v", err)
+		}
+		defer file.Close()
+
+		if _, err := file.WriteString(content); err != nil {
+			t.Fatalf("error appending to the file: %v", err)
+		}
+	}
+}
+
+// rotate appends content to the file and moves it away right after, like
+// logrotate, before creating the new file
+func rotate(content string, newContent string) func(t *testing.T, path string) {
+	return func(t *testing.T, path string) {
+		t.Helper()
+
+		appendFile(content)(t, path)
+
+		if err := os.Rename(path, path+".1"); err != nil {
+			t.Fatalf("error moving the file: %v", err)
+		}
+
+		writeFile(newContent)(t, path)
+	}
+}
+
+func receive(t *testing.T, logSink *sink.Sink, count int) []string {
+	t.Helper()
+
+	messages := []string{}
+	timeout := time.After(waitTimeout)
+
+	for len(messages) < count {
+		select {
+		case logs := <-logSink.NewLog:
+			for _, log := range logs {
+				messages = append(messages, log.Message)
+			}
+		case <-timeout:
+			t.Fatalf("got %v before timing out, want %d messages", messages, count)
+		}
+	}
+
+	return messages
+}
+
+func TestTailer(t *testing.T) {
+	tests := []struct {
+		name      string
+		initial   string
+		fromStart bool
+		steps     []step
+	}{
+		{
+			name:      "from the start",
+			initial:   "first\nsecond\n",
+			fromStart: true,
+			steps: []step{
+				{action: appendFile("third\n"), want: []string{"third"}},
+			},
+		},
+		{
+			name:    "only new lines",
+			initial: "old\n",
+			steps: []step{
+				{action: appendFile("new\n"), want: []string{"new"}},
+			},
+		},
+		{
+			name:      "rotation",
+			initial:   "first\n",
+			fromStart: true,
+			steps: []step{
+				{action: rotate("", "second\n"), want: []string{"second"}},
+				{action: appendFile("third\n"), want: []string{"third"}},
+			},
+		},
+		{
+			name:      "lines written to the old file just before the rotation",
+			initial:   "first\n",
+			fromStart: true,
+			steps: []step{
+				{action: rotate("late\n", "second\n"), want: []string{"late", "second"}},
+			},
+		},
+		{
+			name:      "truncation",
+			initial:   "a long first line\n",
+			fromStart: true,
+			steps: []step{
+				{action: writeFile("short\n"), want: []string{"short"}},
+				{action: appendFile("next\n"), want: []string{"next"}},
+			},
+		},
+		{
+			name:      "partial final line",
+			initial:   "first\n",
+			fromStart: true,
+			steps: []step{
+				{action: appendFile("par")},
+				{action: appendFile("tial\n"), want: []string{"partial"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			writeFile(test.initial)(t, path)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			logSink := sink.NewSink(100)
+
+			tailer := &tailer{path: path, fromStart: test.fromStart}
+			if err := tailer.open(); err != nil {
+				t.Fatalf("error opening the file: %v", err)
+			}
+
+			done := make(chan error, 1)
+			go func() {
+				done <- tailer.run(ctx, logSink)
+			}()
+
+			if test.fromStart {
+				receive(t, logSink, strings.Count(test.initial, "\n"))
+			}
+
+			for i, step := range test.steps {
+				step.action(t, path)
+
+				if len(step.want) == 0 {
+					time.Sleep(3 * pollInterval)
+
+					select {
+					case logs := <-logSink.NewLog:
+						t.Fatalf("step %d: got %d unexpected logs", i, len(logs))
+					default:
+					}
+
+					continue
+				}
+
+				if got := receive(t, logSink, len(step.want)); !slices.Equal(got, step.want) {
+					t.Fatalf("step %d: got %v, want %v", i, got, step.want)
+				}
+			}
+
+			cancel()
+
+			if err := <-done; err != context.Canceled {
+				t.Fatalf("run returned %v, want context.Canceled", err)
+			}
+		})
+	}
+}
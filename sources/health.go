@@ -0,0 +1,95 @@
+package sources
+
+import (
+	"sync"
+	"time"
+)
+
+type State string
+
+const (
+	StateConnected    State = "connected"
+	StateReconnecting State = "reconnecting"
+	// StateFailed means reconnecting keeps failing. The source still
+	// retries, but needs attention
+	StateFailed State = "failed"
+)
+
+// Status is a snapshot of the connection of a source
+type Status struct {
+	Name          string    `json:"name"`
+	State         State     `json:"state"`
+	LastError     string    `json:"lastError,omitempty"`
+	LastErrorAt   time.Time `json:"lastErrorAt"`
+	LastMessageAt time.Time `json:"lastMessageAt"`
+	ConnectedAt   time.Time `json:"connectedAt"`
+}
+
+// Reporter is implemented by sources that hold a connection to their
+// provider. A source may report one status per stream
+type Reporter interface {
+	Health() []Status
+}
+
+// Health tracks the connection of a long running stream. It is safe for
+// concurrent use
+type Health struct {
+	mu     sync.RWMutex
+	status Status
+}
+
+func NewHealth(name string) *Health {
+	return &Health{
+		status: Status{
+			Name:  name,
+			State: StateReconnecting,
+		},
+	}
+}
+
+func (h *Health) Connected() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.status.State = StateConnected
+	h.status.ConnectedAt = time.Now()
+}
+
+// Message records that the stream delivered a message
+func (h *Health) Message() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.status.LastMessageAt = time.Now()
+}
+
+// Failed records why the stream dropped and whether it is still expected
+// to recover
+func (h *Health) Failed(state State, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.status.State = state
+	h.status.LastError = err.Error()
+	h.status.LastErrorAt = time.Now()
+}
+
+func (h *Health) Status() Status {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.status
+}
+
+// Statuses collects the status of every source that reports one
+func Statuses(active []Source) []Status {
+	statuses := []Status{}
+
+	for _, source := range active {
+		if reporter, ok := source.(Reporter); ok {
+			statuses = append(statuses, reporter.Health()...)
+		}
+	}
+
+	return statuses
+}
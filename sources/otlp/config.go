@@ -0,0 +1,24 @@
+package otlp
+
+import (
+	"errors"
+	"os"
+)
+
+type Config struct {
+	// Addr is the listen address of the OTLP/HTTP receiver, such as ":4318"
+	Addr string
+}
+
+// GenerateConfig reads OTLP_HTTP_ADDR
+func GenerateConfig() (*Config, error) {
+	config := Config{
+		Addr: os.Getenv("OTLP_HTTP_ADDR"),
+	}
+
+	if config.Addr == "" {
+		return nil, errors.New("an otlp http address must be present")
+	}
+
+	return &config, nil
+}
@@ -0,0 +1,200 @@
+package otlp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/ferretcode/pricetag/sources"
+	"github.com/ferretcode/pricetag/types"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+)
+
+// unknownService is the service.name the OpenTelemetry SDKs fall back to
+const unknownService = "unknown_service"
+
+// toLogs flattens an export request into logs. Resource attributes are
+// copied onto each of their records, and a record attribute wins over a
+// resource attribute of the same name
+func toLogs(request *collogspb.ExportLogsServiceRequest, now time.Time) []types.Log {
+	logs := []types.Log{}
+
+	for _, resourceLogs := range request.GetResourceLogs() {
+		resource := attributeMap(resourceLogs.GetResource().GetAttributes())
+
+		serviceName := resource["service.name"]
+		if serviceName == "" {
+			serviceName = unknownService
+		}
+
+		environmentName := resource["deployment.environment.name"]
+		if environmentName == "" {
+			environmentName = resource["deployment.environment"]
+		}
+
+		for _, scopeLogs := range resourceLogs.GetScopeLogs() {
+			scope := scopeLogs.GetScope()
+
+			for _, record := range scopeLogs.GetLogRecords() {
+				log := toLog(record, resource, now)
+
+				if scope.GetName() != "" {
+					log.Attributes["otel.scope.name"] = scope.GetName()
+				}
+
+				log.ServiceID = "otlp:" + serviceName
+				log.ServiceName = serviceName
+				log.EnvironmentName = environmentName
+				log.Raw = rawLog(log)
+
+				logs = append(logs, log)
+			}
+		}
+	}
+
+	return logs
+}
+
+func toLog(record *logspb.LogRecord, resource map[string]string, now time.Time) types.Log {
+	attributes := make(map[string]string, len(resource)+len(record.GetAttributes())+3)
+
+	for key, value := range resource {
+		attributes[key] = value
+	}
+
+	for key, value := range attributeMap(record.GetAttributes()) {
+		attributes[key] = value
+	}
+
+	if traceID := record.GetTraceId(); len(traceID) > 0 {
+		attributes["traceId"] = hex.EncodeToString(traceID)
+	}
+
+	if spanID := record.GetSpanId(); len(spanID) > 0 {
+		attributes["spanId"] = hex.EncodeToString(spanID)
+	}
+
+	if record.GetSeverityNumber() != logspb.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED {
+		attributes["severityNumber"] = strconv.Itoa(int(record.GetSeverityNumber()))
+	}
+
+	timestamp := now.UTC()
+
+	switch {
+	case record.GetTimeUnixNano() != 0:
+		timestamp = time.Unix(0, int64(record.GetTimeUnixNano())).UTC()
+	case record.GetObservedTimeUnixNano() != 0:
+		timestamp = time.Unix(0, int64(record.GetObservedTimeUnixNano())).UTC()
+	}
+
+	return types.Log{
+		Message:    valueString(record.GetBody()),
+		Level:      level(record),
+		Timestamp:  timestamp,
+		Attributes: attributes,
+	}
+}
+
+// level prefers the severity text the app logged with and falls back to the
+// severity number ranges of the OpenTelemetry log data model
+func level(record *logspb.LogRecord) string {
+	if record.GetSeverityText() != "" {
+		return sources.NormalizeLevel(record.GetSeverityText())
+	}
+
+	number := record.GetSeverityNumber()
+
+	switch {
+	case number == logspb.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED:
+		return "info"
+	case number < logspb.SeverityNumber_SEVERITY_NUMBER_INFO:
+		return "debug"
+	case number < logspb.SeverityNumber_SEVERITY_NUMBER_WARN:
+		return "info"
+	case number < logspb.SeverityNumber_SEVERITY_NUMBER_ERROR:
+		return "warn"
+	}
+
+	return "error"
+}
+
+// attributeMap flattens attributes to strings, the same way json log lines
+// are: strings as they are and every other value as json
+func attributeMap(attributes []*commonpb.KeyValue) map[string]string {
+	values := make(map[string]string, len(attributes))
+
+	for _, attribute := range attributes {
+		values[attribute.GetKey()] = valueString(attribute.GetValue())
+	}
+
+	return values
+}
+
+func valueString(value *commonpb.AnyValue) string {
+	if s, ok := value.GetValue().(*commonpb.AnyValue_StringValue); ok {
+		return s.StringValue
+	}
+
+	if value.GetValue() == nil {
+		return ""
+	}
+
+	encoded, err := json.Marshal(valueJSON(value))
+	if err != nil {
+		return ""
+	}
+
+	return string(encoded)
+}
+
+// valueJSON converts an AnyValue into the value encoding/json would produce
+// for it
+func valueJSON(value *commonpb.AnyValue) any {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return v.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return v.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return v.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return v.BytesValue
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]any, 0, len(v.ArrayValue.GetValues()))
+		for _, item := range v.ArrayValue.GetValues() {
+			values = append(values, valueJSON(item))
+		}
+		return values
+	case *commonpb.AnyValue_KvlistValue:
+		values := make(map[string]any, len(v.KvlistValue.GetValues()))
+		for _, item := range v.KvlistValue.GetValues() {
+			values[item.GetKey()] = valueJSON(item.GetValue())
+		}
+		return values
+	}
+
+	return nil
+}
+
+// rawLog builds a flat json log line out of the converted log, shaped like
+// the lines of a json logger
+func rawLog(log types.Log) json.RawMessage {
+	fields := make(map[string]string, len(log.Attributes)+3)
+
+	for key, value := range log.Attributes {
+		fields[key] = value
+	}
+
+	fields["message"] = log.Message
+	fields["level"] = log.Level
+	fields["timestamp"] = log.Timestamp.Format(time.RFC3339Nano)
+
+	raw, _ := json.Marshal(fields)
+
+	return raw
+}
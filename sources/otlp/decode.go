@@ -0,0 +1,106 @@
+package otlp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+var errUnsupportedContentType = errors.New("the content type must be " + contentTypeProtobuf + " or " + contentTypeJSON)
+
+func decodeRequest(contentType string, body []byte) (*collogspb.ExportLogsServiceRequest, error) {
+	request := &collogspb.ExportLogsServiceRequest{}
+
+	switch contentType {
+	case contentTypeProtobuf:
+		err := proto.Unmarshal(body, request)
+		if err != nil {
+			return nil, fmt.Errorf("invalid protobuf body: %w", err)
+		}
+	case contentTypeJSON:
+		body, err := hexIDsToBase64(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid json body: %w", err)
+		}
+
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(body, request)
+		if err != nil {
+			return nil, fmt.Errorf("invalid json body: %w", err)
+		}
+	default:
+		return nil, errUnsupportedContentType
+	}
+
+	return request, nil
+}
+
+func encodeResponse(contentType string) ([]byte, error) {
+	response := &collogspb.ExportLogsServiceResponse{}
+
+	if contentType == contentTypeJSON {
+		return protojson.Marshal(response)
+	}
+
+	return proto.Marshal(response)
+}
+
+// hexIDsToBase64 rewrites the trace and span ids of a json request. OTLP/JSON
+// sends them as hex while protojson expects the base64 of every bytes field
+func hexIDsToBase64(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	request := map[string]any{}
+
+	err := decoder.Decode(&request)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resourceLogs := range objects(request["resourceLogs"]) {
+		for _, scopeLogs := range objects(resourceLogs["scopeLogs"]) {
+			for _, record := range objects(scopeLogs["logRecords"]) {
+				for _, key := range []string{"traceId", "spanId"} {
+					id, ok := record[key].(string)
+					if !ok || id == "" {
+						continue
+					}
+
+					decoded, err := hex.DecodeString(id)
+					if err != nil {
+						return nil, fmt.Errorf("%s is not hex encoded", key)
+					}
+
+					record[key] = base64.StdEncoding.EncodeToString(decoded)
+				}
+			}
+		}
+	}
+
+	return json.Marshal(request)
+}
+
+func objects(value any) []map[string]any {
+	items, _ := value.([]any)
+	values := make([]map[string]any, 0, len(items))
+
+	for _, item := range items {
+		if object, ok := item.(map[string]any); ok {
+			values = append(values, object)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,219 @@
+package otlp
+
+import (
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/ferretcode/pricetag/types"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const jsonRequest = `{
+	"resourceLogs": [{
+		"resource": {
+			"attributes": [
+				{"key": "service.name", "value": {"stringValue": "checkout"}},
+				{"key": "deployment.environment", "value": {"stringValue": "production"}}
+			]
+		},
+		"scopeLogs": [{
+			"scope": {"name": "checkout.http"},
+			"logRecords": [{
+				"timeUnixNano": "1710072000000000000",
+				"severityNumber": 17,
+				"severityText": "ERROR",
+				"traceId": "5b8efff798038103d269b633813fc60c",
+				"spanId": "eee19b7ec3c1b174",
+				"body": {"stringValue": "payment failed"},
+				"attributes": [
+					{"key": "http.status_code", "value": {"intValue": "502"}},
+					{"key": "retry", "value": {"boolValue": true}}
+				],
+				"unknownField": "ignored"
+			}]
+		}]
+	}]
+}`
+
+func protobufRequest(t *testing.T) []byte {
+	t.Helper()
+
+	request := &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource: &resourcepb.Resource{
+				Attributes: []*commonpb.KeyValue{
+					stringAttribute("service.name", "checkout"),
+					stringAttribute("deployment.environment", "production"),
+				},
+			},
+			ScopeLogs: []*logspb.ScopeLogs{{
+				Scope: &commonpb.InstrumentationScope{Name: "checkout.http"},
+				LogRecords: []*logspb.LogRecord{{
+					TimeUnixNano:   1710072000000000000,
+					SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_ERROR,
+					SeverityText:   "ERROR",
+					TraceId:        []byte{0x5b, 0x8e, 0xff, 0xf7, 0x98, 0x03, 0x81, 0x03, 0xd2, 0x69, 0xb6, 0x33, 0x81, 0x3f, 0xc6, 0x0c},
+					SpanId:         []byte{0xee, 0xe1, 0x9b, 0x7e, 0xc3, 0xc1, 0xb1, 0x74},
+					Body:           &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "payment failed"}},
+					Attributes: []*commonpb.KeyValue{
+						{Key: "http.status_code", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: 502}}},
+						{Key: "retry", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: true}}},
+					},
+				}},
+			}},
+		}},
+	}
+
+	body, err := proto.Marshal(request)
+	if err != nil {
+		t.Fatalf("error encoding the request: %v", err)
+	}
+
+	return body
+}
+
+func stringAttribute(key string, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+func TestDecodeRequest(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 13, 0, 0, 0, time.UTC)
+
+	want := types.Log{
+		Message:         "payment failed",
+		Level:           "error",
+		Timestamp:       time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC),
+		ServiceID:       "otlp:checkout",
+		ServiceName:     "checkout",
+		EnvironmentName: "production",
+		Attributes: map[string]string{
+			"service.name":           "checkout",
+			"deployment.environment": "production",
+			"http.status_code":       "502",
+			"retry":                  "true",
+			"traceId":                "5b8efff798038103d269b633813fc60c",
+			"spanId":                 "eee19b7ec3c1b174",
+			"severityNumber":         "17",
+			"otel.scope.name":        "checkout.http",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		wantErr     bool
+	}{
+		{
+			name:        "json",
+			contentType: contentTypeJSON,
+			body:        []byte(jsonRequest),
+		},
+		{
+			name:        "protobuf",
+			contentType: contentTypeProtobuf,
+			body:        protobufRequest(t),
+		},
+		{
+			name:        "json with an id that isn't hex",
+			contentType: contentTypeJSON,
+			body:        []byte(`{"resourceLogs": [{"scopeLogs": [{"logRecords": [{"traceId": "not hex"}]}]}]}`),
+			wantErr:     true,
+		},
+		{
+			name:        "invalid json",
+			contentType: contentTypeJSON,
+			body:        []byte(`{"resourceLogs": [`),
+			wantErr:     true,
+		},
+		{
+			name:        "invalid protobuf",
+			contentType: contentTypeProtobuf,
+			body:        []byte{0xff, 0xff, 0xff},
+			wantErr:     true,
+		},
+		{
+			name:        "unsupported content type",
+			contentType: "text/plain",
+			body:        []byte("payment failed"),
+			wantErr:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request, err := decodeRequest(test.contentType, test.body)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			logs := toLogs(request, now)
+			if len(logs) != 1 {
+				t.Fatalf("got %d logs, want 1", len(logs))
+			}
+
+			got := logs[0]
+
+			if got.Message != want.Message || got.Level != want.Level {
+				t.Errorf("message and level = %q %q, want %q %q", got.Message, got.Level, want.Message, want.Level)
+			}
+
+			if !got.Timestamp.Equal(want.Timestamp) {
+				t.Errorf("timestamp = %v, want %v", got.Timestamp, want.Timestamp)
+			}
+
+			if got.ServiceID != want.ServiceID || got.ServiceName != want.ServiceName || got.EnvironmentName != want.EnvironmentName {
+				t.Errorf("service and environment = %q %q %q, want %q %q %q", got.ServiceID, got.ServiceName, got.EnvironmentName, want.ServiceID, want.ServiceName, want.EnvironmentName)
+			}
+
+			if !maps.Equal(got.Attributes, want.Attributes) {
+				t.Errorf("attributes = %v, want %v", got.Attributes, want.Attributes)
+			}
+		})
+	}
+}
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		name   string
+		record *logspb.LogRecord
+		want   string
+	}{
+		{name: "unspecified", record: &logspb.LogRecord{}, want: "info"},
+		{name: "trace", record: &logspb.LogRecord{SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_TRACE2}, want: "debug"},
+		{name: "info", record: &logspb.LogRecord{SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_INFO4}, want: "info"},
+		{name: "warn", record: &logspb.LogRecord{SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_WARN}, want: "warn"},
+		{name: "fatal", record: &logspb.LogRecord{SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_FATAL}, want: "error"},
+		{
+			name: "text wins over the number",
+			record: &logspb.LogRecord{
+				SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_ERROR,
+				SeverityText:   "warning",
+			},
+			want: "warn",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := level(test.record); got != test.want {
+				t.Errorf("level() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
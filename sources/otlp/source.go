@@ -0,0 +1,251 @@
+// Package otlp receives OpenTelemetry logs over OTLP/HTTP, encoded as
+// protobuf or json
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/sink"
+	"github.com/ferretcode/pricetag/token"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// logsPath is the path OTLP/HTTP exporters send logs to by default
+	logsPath    = "/v1/logs"
+	maxBodySize = 10 << 20
+	// sinkTimeout is how long an export waits for room in the sink before
+	// the exporter is told to retry
+	sinkTimeout = 5 * time.Second
+)
+
+var (
+	errInvalidIngestKey = errors.New("invalid ingest key")
+	errSinkSaturated    = errors.New("pricetag is busy, retry later")
+)
+
+type Source struct {
+	config *Config
+	db     *sqlx.DB
+
+	mu sync.Mutex
+	// err is the last reason the listener stopped, nil while it runs
+	err error
+}
+
+func NewSource(config *Config, db *sqlx.DB) *Source {
+	return &Source{
+		config: config,
+		db:     db,
+	}
+}
+
+func (s *Source) Name() string {
+	return "otlp"
+}
+
+// Start serves the receiver until the context is cancelled or the listener
+// fails
+func (s *Source) Start(ctx context.Context, logSink *sink.Sink) error {
+	listener, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		s.setErr(err)
+		return err
+	}
+
+	log.Info("listening for otlp logs", "addr", listener.Addr())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(logsPath, func(w http.ResponseWriter, r *http.Request) {
+		status, err := export(w, r, s.db, logSink)
+		if err != nil {
+			if status >= 500 {
+				log.Error("error exporting otlp logs", "err", err)
+			}
+
+			http.Error(w, err.Error(), status)
+		}
+	})
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		server.Shutdown(shutdownCtx)
+	}()
+
+	s.setErr(nil)
+
+	err = server.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return ctx.Err()
+	}
+
+	s.setErr(err)
+
+	return err
+}
+
+// HealthCheck returns the error that last stopped the listener
+func (s *Source) HealthCheck(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+func (s *Source) setErr(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// export handles one OTLP/HTTP export request. Exporters authenticate with an
+// ingest key, like /ingest. The response uses the encoding of the request, as
+// the protocol requires
+func export(w http.ResponseWriter, r *http.Request, db *sqlx.DB, logSink *sink.Sink) (status int, err error) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		return 405, errors.New("only POST is supported")
+	}
+
+	status, err = authorize(r, db)
+	if err != nil {
+		return status, err
+	}
+
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return 415, errUnsupportedContentType
+	}
+
+	body, err := readBody(w, r)
+	if err != nil {
+		maxBytesError := &http.MaxBytesError{}
+		if errors.As(err, &maxBytesError) {
+			return 413, errors.New("the request body cannot be over " + strconv.Itoa(maxBodySize) + " bytes")
+		}
+		return 400, err
+	}
+
+	request, err := decodeRequest(contentType, body)
+	if err != nil {
+		if err == errUnsupportedContentType {
+			return 415, err
+		}
+		return 400, err
+	}
+
+	logs := toLogs(request, time.Now())
+
+	if len(logs) > 0 {
+		timer := time.NewTimer(sinkTimeout)
+		defer timer.Stop()
+
+		select {
+		case logSink.NewLog <- logs:
+		case <-timer.C:
+			w.Header().Set("Retry-After", strconv.Itoa(int(sinkTimeout.Seconds())))
+			return 503, errSinkSaturated
+		case <-r.Context().Done():
+			return 503, r.Context().Err()
+		}
+	}
+
+	response, err := encodeResponse(contentType)
+	if err != nil {
+		return 500, err
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(200)
+
+	_, err = w.Write(response)
+	if err != nil {
+		return 500, err
+	}
+
+	return 200, nil
+}
+
+// authorize checks the ingest key of the request against the IngestSource
+// table
+func authorize(r *http.Request, db *sqlx.DB) (status int, err error) {
+	ingestKey := token.IngestKey(r)
+	if ingestKey == "" {
+		return 401, errInvalidIngestKey
+	}
+
+	selectIngestSourceQuery := squirrel.
+		Select("COUNT(*)").
+		From("IngestSource").
+		Where(squirrel.Eq{"KeyHash": token.Hash(ingestKey)})
+
+	sql, args, err := selectIngestSourceQuery.ToSql()
+	if err != nil {
+		return 500, err
+	}
+
+	count := 0
+
+	err = db.Get(&count, sql, args...)
+	if err != nil {
+		return 500, err
+	}
+
+	if count == 0 {
+		return 401, errInvalidIngestKey
+	}
+
+	return 200, nil
+}
+
+// readBody reads the request body, which exporters may gzip
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	var body io.Reader = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+	switch r.Header.Get("Content-Encoding") {
+	case "", "identity":
+	case "gzip":
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+
+		// the decompressed size is capped too, so a small body can't
+		// expand without bound
+		body = io.LimitReader(reader, maxBodySize+1)
+	default:
+		return nil, errors.New("unsupported content encoding " + r.Header.Get("Content-Encoding"))
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxBodySize {
+		return nil, &http.MaxBytesError{Limit: maxBodySize}
+	}
+
+	return data, nil
+}
@@ -0,0 +1,190 @@
+package sources
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ferretcode/pricetag/types"
+)
+
+// Keys names the fields of a json log line holding the message, level and
+// timestamp. The first key present wins
+type Keys struct {
+	Message   []string
+	Level     []string
+	Timestamp []string
+}
+
+// DefaultKeys covers the field names of the common json loggers
+var DefaultKeys = Keys{
+	Message:   []string{"message", "msg"},
+	Level:     []string{"level", "severity", "lvl"},
+	Timestamp: []string{"timestamp", "time", "ts"},
+}
+
+var errNotObject = errors.New("log line is not a json object")
+
+// ParseLine parses a json object log line, anything else is read as plain
+// text
+func ParseLine(line []byte, keys Keys) types.Log {
+	line = bytes.TrimSpace(line)
+
+	if len(line) > 0 && line[0] == '{' {
+		log, err := ParseJSON(line, keys)
+		if err == nil {
+			return log
+		}
+	}
+
+	return ParseText(string(line))
+}
+
+// ParseJSON converts a json object into a log. Every field except the
+// message, level and timestamp becomes an attribute, string values are
+// unquoted and other values are kept as json
+func ParseJSON(data []byte, keys Keys) (types.Log, error) {
+	fields := map[string]json.RawMessage{}
+
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return types.Log{}, err
+	}
+
+	if fields == nil {
+		return types.Log{}, errNotObject
+	}
+
+	log := types.Log{
+		Level:      "info",
+		Timestamp:  time.Now().UTC(),
+		Attributes: make(map[string]string, len(fields)),
+	}
+
+	if key, ok := firstKey(fields, keys.Message); ok {
+		log.Message = rawString(fields[key])
+		delete(fields, key)
+	}
+
+	if key, ok := firstKey(fields, keys.Level); ok {
+		log.Level = NormalizeLevel(rawString(fields[key]))
+		delete(fields, key)
+	}
+
+	if key, ok := firstKey(fields, keys.Timestamp); ok {
+		timestamp, err := parseTimestamp(fields[key])
+		if err == nil {
+			log.Timestamp = timestamp
+			delete(fields, key)
+		}
+	}
+
+	for key, value := range fields {
+		log.Attributes[key] = rawString(value)
+	}
+
+	raw := &bytes.Buffer{}
+
+	err = json.Compact(raw, data)
+	if err != nil {
+		return types.Log{}, err
+	}
+
+	log.Raw = raw.Bytes()
+
+	return log, nil
+}
+
+// ParseText wraps a plain text line in a log
+func ParseText(line string) types.Log {
+	log := types.Log{
+		Message:    line,
+		Level:      "info",
+		Timestamp:  time.Now().UTC(),
+		Attributes: map[string]string{},
+	}
+
+	log.Raw, _ = json.Marshal(map[string]string{
+		"message":   log.Message,
+		"level":     log.Level,
+		"timestamp": log.Timestamp.Format(time.RFC3339Nano),
+	})
+
+	return log
+}
+
+// NormalizeLevel maps the common spellings of a severity onto debug, info,
+// warn and error. Unknown levels are only lowercased
+func NormalizeLevel(level string) string {
+	level = strings.ToLower(strings.TrimSpace(level))
+
+	switch level {
+	case "trace", "debug", "dbg":
+		return "debug"
+	case "", "info", "information", "notice":
+		return "info"
+	case "warn", "warning":
+		return "warn"
+	case "err", "error", "fatal", "panic", "critical", "crit", "alert", "emerg", "emergency":
+		return "error"
+	}
+
+	return level
+}
+
+func firstKey(fields map[string]json.RawMessage, keys []string) (string, bool) {
+	for _, key := range keys {
+		if _, ok := fields[key]; ok {
+			return key, true
+		}
+	}
+
+	return "", false
+}
+
+// rawString unquotes json strings and returns any other value as json
+func rawString(value json.RawMessage) string {
+	var s string
+
+	if err := json.Unmarshal(value, &s); err == nil {
+		return s
+	}
+
+	return string(value)
+}
+
+// parseTimestamp accepts RFC 3339 strings and unix times in seconds,
+// milliseconds, microseconds or nanoseconds
+func parseTimestamp(value json.RawMessage) (time.Time, error) {
+	var s string
+
+	if err := json.Unmarshal(value, &s); err == nil {
+		parsed, err := time.Parse(time.RFC3339Nano, s)
+		if err == nil {
+			return parsed.UTC(), nil
+		}
+
+		value = json.RawMessage(s)
+	}
+
+	number, err := strconv.ParseFloat(string(value), 64)
+	if err != nil {
+		return time.Time{}, errors.New("invalid timestamp")
+	}
+
+	switch {
+	case number > 1e17:
+		return time.Unix(0, int64(number)).UTC(), nil
+	case number > 1e14:
+		return time.UnixMicro(int64(number)).UTC(), nil
+	case number > 1e11:
+		return time.UnixMilli(int64(number)).UTC(), nil
+	}
+
+	seconds := int64(number)
+
+	return time.Unix(seconds, int64((number-float64(seconds))*1e9)).UTC(), nil
+}
@@ -3,29 +3,105 @@ package railway
 import (
 	"errors"
 	"os"
+	"slices"
+	"sync"
+
+	"github.com/ferretcode/pricetag/types"
 )
 
 type Config struct {
-	ApiKey        string
-	EnvironmentId string
-	ServiceIds    []string
+	ApiKey string
+	// TaggedOnly drops logs that match no tag, which lets the tags narrow
+	// the server side filter
+	TaggedOnly bool
+
+	// ServiceIds holds the tracked services. Once the subscription has
+	// started it must only be accessed through SetServiceIds and IsTracked
+	ServiceIds []string
+	mu         sync.RWMutex
+	// servicesChanged is closed by the next SetServiceIds
+	servicesChanged chan struct{}
+
+	// environments are streamed by the source, which is told about changes
+	// through changed
+	environments []types.RailwayEnvironment
+	changed      chan struct{}
 }
 
-func GenerateConfig(serviceIds []string) (*Config, error) {
+// GenerateConfig reads the api key. The environments come from the
+// RailwayEnvironment table
+func GenerateConfig(serviceIds []string, environments []types.RailwayEnvironment) (*Config, error) {
 	config := Config{}
 
 	apiKey := os.Getenv("RAILWAY_API_KEY")
-	environtmentId := os.Getenv("RAILWAY_ENVIRONMENT_ID")
 
 	if apiKey == "" {
 		return nil, errors.New("api key must be present")
 	}
 
-	if environtmentId == "" {
-		return nil, errors.New("environment id must be present")
-	}
-
+	config.ApiKey = apiKey
+	config.TaggedOnly = os.Getenv("RAILWAY_TAGGED_ONLY") == "true"
 	config.ServiceIds = serviceIds
+	config.servicesChanged = make(chan struct{})
+	config.environments = environments
+	config.changed = make(chan struct{}, 1)
 
 	return &config, nil
 }
+
+func (c *Config) SetServiceIds(serviceIds []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ServiceIds = serviceIds
+	close(c.servicesChanged)
+	c.servicesChanged = make(chan struct{})
+}
+
+// TrackedServiceIds returns a copy of the tracked services
+func (c *Config) TrackedServiceIds() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Clone(c.ServiceIds)
+}
+
+// ServicesChanged is closed the next time the tracked services change
+func (c *Config) ServicesChanged() <-chan struct{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.servicesChanged
+}
+
+func (c *Config) IsTracked(serviceId string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Contains(c.ServiceIds, serviceId)
+}
+
+// SetEnvironments replaces the streamed environments. The running source
+// starts and stops streams to match
+func (c *Config) SetEnvironments(environments []types.RailwayEnvironment) {
+	c.mu.Lock()
+	c.environments = environments
+	c.mu.Unlock()
+
+	select {
+	case c.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Config) Environments() []types.RailwayEnvironment {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Clone(c.environments)
+}
+
+// Changed receives after SetEnvironments
+func (c *Config) Changed() <-chan struct{} {
+	return c.changed
+}
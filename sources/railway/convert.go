@@ -0,0 +1,63 @@
+package railway
+
+import (
+	"strconv"
+
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/types"
+)
+
+// toLogs converts the logs that can be reconstructed. A log that can't be is
+// dropped, fetching it again would fail the same way
+func toLogs(logs []railwayLog) []types.Log {
+	newLogs := make([]types.Log, 0, len(logs))
+
+	for i := range logs {
+		newLog, err := toLog(logs[i])
+		if err != nil {
+			log.Warn("dropping a railway log that can't be reconstructed", "service", logs[i].Tags.ServiceName, "timestamp", logs[i].Timestamp, "err", err)
+			continue
+		}
+
+		newLogs = append(newLogs, newLog)
+	}
+
+	return newLogs
+}
+
+func toLog(log railwayLog) (types.Log, error) {
+	raw, err := ReconstructLogLine(log)
+	if err != nil {
+		return types.Log{}, err
+	}
+
+	attributes := make(map[string]string, len(log.Attributes))
+
+	for i := range log.Attributes {
+		// attribute values are raw json, so strings arrive quoted
+		value, err := strconv.Unquote(log.Attributes[i].Value)
+		if err != nil {
+			value = log.Attributes[i].Value
+		}
+
+		attributes[log.Attributes[i].Key] = value
+	}
+
+	return types.Log{
+		Message:    log.Message,
+		Level:      log.Severity,
+		Timestamp:  log.Timestamp,
+		Attributes: attributes,
+
+		ServiceID:            log.Tags.ServiceID,
+		ServiceName:          log.Tags.ServiceName,
+		EnvironmentID:        log.Tags.EnvironmentID,
+		EnvironmentName:      log.Tags.EnvironmentName,
+		ProjectID:            log.Tags.ProjectID,
+		ProjectName:          log.Tags.ProjectName,
+		DeploymentID:         log.Tags.DeploymentID,
+		DeploymentInstanceID: log.Tags.DeploymentInstanceID,
+
+		Raw: raw,
+	}, nil
+}
@@ -0,0 +1,152 @@
+package railway
+
+import (
+	"database/sql"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// Cursor is the position of the last log delivered from an environment.
+// Several logs can share a timestamp, so the cursor also remembers which of
+// the logs at its timestamp were delivered
+type Cursor struct {
+	Timestamp time.Time
+	// Seen holds a fingerprint per log delivered at Timestamp. Identical logs
+	// have the same fingerprint, so it may appear more than once
+	Seen []string
+}
+
+// Advance returns the logs, sorted by time, that come after the cursor, and
+// the cursor past them. Logs at the cursor's timestamp are only skipped as
+// often as they were seen
+func (c Cursor) Advance(logs []railwayLog) (fresh []railwayLog, next Cursor) {
+	seen := map[string]int{}
+	for _, fingerprint := range c.Seen {
+		seen[fingerprint]++
+	}
+
+	next = Cursor{
+		Timestamp: c.Timestamp,
+		Seen:      slices.Clone(c.Seen),
+	}
+
+	for _, log := range logs {
+		if log.Timestamp.Before(c.Timestamp) {
+			continue
+		}
+
+		fingerprint := logFingerprint(log)
+
+		if log.Timestamp.Equal(c.Timestamp) && seen[fingerprint] > 0 {
+			seen[fingerprint]--
+			continue
+		}
+
+		if log.Timestamp.After(next.Timestamp) {
+			next = Cursor{
+				Timestamp: log.Timestamp,
+			}
+		}
+
+		next.Seen = append(next.Seen, fingerprint)
+		fresh = append(fresh, log)
+	}
+
+	return fresh, next
+}
+
+// logFingerprint identifies a log among the logs at the same timestamp
+func logFingerprint(log railwayLog) string {
+	hash := fnv.New64a()
+
+	for _, field := range []string{log.Tags.DeploymentInstanceID, log.Tags.ServiceID, log.Severity, log.Message} {
+		hash.Write([]byte(field))
+		hash.Write([]byte{0})
+	}
+
+	for _, attribute := range log.Attributes {
+		hash.Write([]byte(attribute.Key))
+		hash.Write([]byte{0})
+		hash.Write([]byte(attribute.Value))
+		hash.Write([]byte{0})
+	}
+
+	return strconv.FormatUint(hash.Sum64(), 16)
+}
+
+type cursorRow struct {
+	EnvironmentID string `db:"EnvironmentID"`
+	Timestamp     int64  `db:"Timestamp"`
+	// Seen is the comma separated fingerprints
+	Seen      string `db:"Seen"`
+	UpdatedAt int64  `db:"UpdatedAt"`
+}
+
+// CursorStore persists a cursor per environment so a restart resumes where
+// the last run stopped
+type CursorStore struct {
+	db *sqlx.DB
+}
+
+func NewCursorStore(db *sqlx.DB) *CursorStore {
+	return &CursorStore{
+		db: db,
+	}
+}
+
+// Load returns the cursor of an environment, ok is false when the
+// environment has never been streamed
+func (s *CursorStore) Load(environmentID string) (cursor Cursor, ok bool, err error) {
+	selectCursorQuery := squirrel.
+		Select("*").
+		From("RailwayCursor").
+		Where(squirrel.Eq{"EnvironmentID": environmentID})
+
+	query, args, err := selectCursorQuery.ToSql()
+	if err != nil {
+		return Cursor{}, false, err
+	}
+
+	row := cursorRow{}
+
+	err = s.db.Get(&row, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Cursor{}, false, nil
+		}
+		return Cursor{}, false, err
+	}
+
+	cursor = Cursor{
+		// timestamps are stored as unix nanoseconds, like log timestamps
+		Timestamp: time.Unix(0, row.Timestamp).UTC(),
+	}
+
+	if row.Seen != "" {
+		cursor.Seen = strings.Split(row.Seen, ",")
+	}
+
+	return cursor, true, nil
+}
+
+func (s *CursorStore) Save(environmentID string, cursor Cursor) error {
+	saveCursorQuery := squirrel.
+		Insert("RailwayCursor").
+		Columns("EnvironmentID", "Timestamp", "Seen", "UpdatedAt").
+		Values(environmentID, cursor.Timestamp.UnixNano(), strings.Join(cursor.Seen, ","), time.Now().Unix()).
+		Suffix("ON CONFLICT (EnvironmentID) DO UPDATE SET Timestamp = excluded.Timestamp, Seen = excluded.Seen, UpdatedAt = excluded.UpdatedAt")
+
+	query, args, err := saveCursorQuery.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(query, args...)
+	return err
+}
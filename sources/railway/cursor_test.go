@@ -0,0 +1,128 @@
+package railway
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func testLog(timestamp time.Time, message string) railwayLog {
+	log := railwayLog{
+		Timestamp: timestamp,
+		Message:   message,
+		Severity:  "info",
+	}
+	log.Tags.ServiceID = "svc1"
+	log.Tags.DeploymentInstanceID = "inst1"
+
+	return log
+}
+
+func TestCursorAdvance(t *testing.T) {
+	t0 := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Millisecond)
+	t2 := t0.Add(2 * time.Millisecond)
+
+	tests := []struct {
+		name   string
+		cursor Cursor
+		logs   []railwayLog
+		// fresh are the messages expected to be delivered
+		fresh []string
+		next  Cursor
+	}{
+		{
+			name:   "skips logs before the cursor",
+			cursor: Cursor{Timestamp: t1},
+			logs:   []railwayLog{testLog(t0, "old"), testLog(t1, "a"), testLog(t2, "b")},
+			fresh:  []string{"a", "b"},
+			next:   Cursor{Timestamp: t2, Seen: []string{logFingerprint(testLog(t2, "b"))}},
+		},
+		{
+			name:   "skips only the seen logs at the cursor",
+			cursor: Cursor{Timestamp: t1, Seen: []string{logFingerprint(testLog(t1, "a"))}},
+			logs:   []railwayLog{testLog(t1, "a"), testLog(t1, "b")},
+			fresh:  []string{"b"},
+			next: Cursor{Timestamp: t1, Seen: []string{
+				logFingerprint(testLog(t1, "a")),
+				logFingerprint(testLog(t1, "b")),
+			}},
+		},
+		{
+			name:   "delivers a repeat of a seen log",
+			cursor: Cursor{Timestamp: t1, Seen: []string{logFingerprint(testLog(t1, "a"))}},
+			logs:   []railwayLog{testLog(t1, "a"), testLog(t1, "a")},
+			fresh:  []string{"a"},
+			next: Cursor{Timestamp: t1, Seen: []string{
+				logFingerprint(testLog(t1, "a")),
+				logFingerprint(testLog(t1, "a")),
+			}},
+		},
+		{
+			name:   "a newer timestamp resets the seen logs",
+			cursor: Cursor{Timestamp: t1, Seen: []string{logFingerprint(testLog(t1, "a"))}},
+			logs:   []railwayLog{testLog(t1, "a"), testLog(t2, "b"), testLog(t2, "c")},
+			fresh:  []string{"b", "c"},
+			next: Cursor{Timestamp: t2, Seen: []string{
+				logFingerprint(testLog(t2, "b")),
+				logFingerprint(testLog(t2, "c")),
+			}},
+		},
+		{
+			name:   "nothing new keeps the cursor",
+			cursor: Cursor{Timestamp: t1, Seen: []string{logFingerprint(testLog(t1, "a"))}},
+			logs:   []railwayLog{testLog(t0, "old"), testLog(t1, "a")},
+			fresh:  nil,
+			next:   Cursor{Timestamp: t1, Seen: []string{logFingerprint(testLog(t1, "a"))}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fresh, next := test.cursor.Advance(test.logs)
+
+			messages := []string{}
+			for _, log := range fresh {
+				messages = append(messages, log.Message)
+			}
+
+			if !slices.Equal(messages, test.fresh) {
+				t.Errorf("fresh = %v, want %v", messages, test.fresh)
+			}
+
+			if !next.Timestamp.Equal(test.next.Timestamp) {
+				t.Errorf("next timestamp = %v, want %v", next.Timestamp, test.next.Timestamp)
+			}
+
+			if !slices.Equal(next.Seen, test.next.Seen) {
+				t.Errorf("next seen = %v, want %v", next.Seen, test.next.Seen)
+			}
+		})
+	}
+}
+
+func TestLogFingerprint(t *testing.T) {
+	t0 := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	base := testLog(t0, "message")
+
+	otherInstance := testLog(t0, "message")
+	otherInstance.Tags.DeploymentInstanceID = "inst2"
+
+	withAttribute := testLog(t0, "message")
+	withAttribute.Attributes = []attributes{{Key: "key", Value: "value"}}
+
+	if logFingerprint(base) != logFingerprint(testLog(t0, "message")) {
+		t.Error("identical logs have different fingerprints")
+	}
+
+	for name, other := range map[string]railwayLog{
+		"message":    testLog(t0, "other"),
+		"instance":   otherInstance,
+		"attributes": withAttribute,
+	} {
+		if logFingerprint(base) == logFingerprint(other) {
+			t.Errorf("logs differing in %s have the same fingerprint", name)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package railway
+
+import (
+	"strings"
+
+	"github.com/ferretcode/pricetag/types"
+)
+
+// buildFilter compiles the tracked services into Railway's log filter, and
+// with TaggedOnly the simple keyword and attribute tags too. The filter only
+// saves bandwidth, every log is still checked client side, so whatever the
+// filter syntax can't express is left out of it rather than approximated
+func buildFilter(serviceIDs []string, tags []types.Tag, taggedOnly bool) string {
+	terms := []string{}
+
+	services := []string{}
+	for _, serviceID := range serviceIDs {
+		if !isFilterWord(serviceID) {
+			// a service id that can't be written out means the service list
+			// can't narrow anything down
+			services = nil
+			break
+		}
+
+		services = append(services, "@service:"+serviceID)
+	}
+
+	if len(services) > 0 {
+		terms = append(terms, group(services))
+	}
+
+	// untagged logs may only be left out when they are dropped anyway
+	if taggedOnly && len(tags) > 0 {
+		predicates := []string{}
+
+		for _, tag := range tags {
+			predicate, ok := tagFilter(tag)
+			if !ok {
+				// the tag would have to match anything, so the tags can't
+				// narrow anything down
+				predicates = nil
+				break
+			}
+
+			predicates = append(predicates, predicate)
+		}
+
+		if len(predicates) > 0 {
+			terms = append(terms, group(predicates))
+		}
+	}
+
+	return strings.Join(terms, " AND ")
+}
+
+// tagFilter compiles the criteria of a tag, ok is false when one of them
+// can't be expressed
+func tagFilter(tag types.Tag) (predicate string, ok bool) {
+	criteria := []string{}
+
+	if tag.ServiceID != "" {
+		if !isFilterWord(tag.ServiceID) {
+			return "", false
+		}
+
+		criteria = append(criteria, "@service:"+tag.ServiceID)
+	}
+
+	if tag.Keyword != "" {
+		keyword, ok := quote(tag.Keyword)
+		if !ok {
+			return "", false
+		}
+
+		criteria = append(criteria, keyword)
+	}
+
+	if tag.AttributeKey != "" {
+		// matching any value of an attribute has no filter syntax
+		if !isFilterWord(tag.AttributeKey) || tag.AttributeValue == "" {
+			return "", false
+		}
+
+		value, ok := quote(tag.AttributeValue)
+		if !ok {
+			return "", false
+		}
+
+		criteria = append(criteria, "@"+tag.AttributeKey+":"+value)
+	}
+
+	// a tag without criteria never matches, which the filter can't say
+	if len(criteria) == 0 {
+		return "", false
+	}
+
+	if len(criteria) == 1 {
+		return criteria[0], true
+	}
+
+	return "(" + strings.Join(criteria, " AND ") + ")", true
+}
+
+func group(terms []string) string {
+	if len(terms) == 1 {
+		return terms[0]
+	}
+
+	return "(" + strings.Join(terms, " OR ") + ")"
+}
+
+// quote wraps text in double quotes. Text holding quotes or backslashes is
+// left to client side filtering
+func quote(text string) (string, bool) {
+	if strings.ContainsAny(text, `"\`) {
+		return "", false
+	}
+
+	return `"` + text + `"`, true
+}
+
+// isFilterWord reports whether s can be written into a filter as is
+func isFilterWord(s string) bool {
+	if s == "" || strings.EqualFold(s, "and") || strings.EqualFold(s, "or") || strings.HasPrefix(s, "-") {
+		return false
+	}
+
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' || r == '.') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,117 @@
+package railway
+
+import (
+	"testing"
+
+	"github.com/ferretcode/pricetag/types"
+)
+
+func TestBuildFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		serviceIDs []string
+		tags       []types.Tag
+		taggedOnly bool
+		want       string
+	}{
+		{
+			name:       "one service",
+			serviceIDs: []string{"4a7c1b9e-0f6d-4d8a-9a43-6a1d2e1f0c55"},
+			want:       "@service:4a7c1b9e-0f6d-4d8a-9a43-6a1d2e1f0c55",
+		},
+		{
+			name:       "several services",
+			serviceIDs: []string{"svc1", "svc2", "svc3"},
+			want:       "(@service:svc1 OR @service:svc2 OR @service:svc3)",
+		},
+		{
+			name:       "no services",
+			serviceIDs: nil,
+			want:       "",
+		},
+		{
+			name:       "a service that can't be written out",
+			serviceIDs: []string{"svc1", "svc 2"},
+			want:       "",
+		},
+		{
+			name:       "a service named like an operator",
+			serviceIDs: []string{"svc1", "OR"},
+			want:       "",
+		},
+		{
+			name:       "a service that reads as a negation",
+			serviceIDs: []string{"-svc1"},
+			want:       "",
+		},
+		{
+			name:       "tags are left out unless tagged only",
+			serviceIDs: []string{"svc1"},
+			tags:       []types.Tag{{Keyword: "timeout"}},
+			want:       "@service:svc1",
+		},
+		{
+			name:       "keyword tag",
+			serviceIDs: []string{"svc1"},
+			tags:       []types.Tag{{Keyword: "timeout"}},
+			taggedOnly: true,
+			want:       `@service:svc1 AND "timeout"`,
+		},
+		{
+			name:       "keyword and attribute tags",
+			serviceIDs: []string{"svc1", "svc2"},
+			tags: []types.Tag{
+				{Keyword: "connection refused"},
+				{AttributeKey: "level", AttributeValue: "error"},
+			},
+			taggedOnly: true,
+			want:       `(@service:svc1 OR @service:svc2) AND ("connection refused" OR @level:"error")`,
+		},
+		{
+			name:       "tag with several criteria",
+			serviceIDs: []string{"svc1"},
+			tags:       []types.Tag{{ServiceID: "svc1", Keyword: "panic"}},
+			taggedOnly: true,
+			want:       `@service:svc1 AND (@service:svc1 AND "panic")`,
+		},
+		{
+			name:       "tag on any value of an attribute",
+			serviceIDs: []string{"svc1"},
+			tags: []types.Tag{
+				{Keyword: "timeout"},
+				{AttributeKey: "requestId"},
+			},
+			taggedOnly: true,
+			want:       "@service:svc1",
+		},
+		{
+			name:       "keyword holding a quote",
+			serviceIDs: []string{"svc1"},
+			tags:       []types.Tag{{Keyword: `say "hi"`}},
+			taggedOnly: true,
+			want:       "@service:svc1",
+		},
+		{
+			name:       "tag without criteria",
+			serviceIDs: []string{"svc1"},
+			tags:       []types.Tag{{Name: "empty"}},
+			taggedOnly: true,
+			want:       "@service:svc1",
+		},
+		{
+			name:       "tags without expressible services",
+			serviceIDs: []string{"svc 1"},
+			tags:       []types.Tag{{Keyword: "timeout"}},
+			taggedOnly: true,
+			want:       `"timeout"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := buildFilter(test.serviceIDs, test.tags, test.taggedOnly); got != test.want {
+				t.Errorf("buildFilter() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -27,7 +27,7 @@ func ReconstructLogLines(logs []railwayLog) ([]byte, error) {
 		}
 	}
 
-	jsonObject = append(jsonObject, []byte(`[`)...)
+	jsonObject = append(jsonObject, []byte(`]`)...)
 
 	return jsonObject, nil
 }
@@ -40,7 +40,7 @@ var environmentQuery = `query environment($id: String!) {
 var streamEnvironmentLogsQuery = `subscription streamEnvironmentLogs(
 		$environmentId: String!
 		$filter: String
-		$beforeLimit: Int!
+		$beforeLimit: Int
 		$beforeDate: String
 		$anchorDate: String
 		$afterDate: String
@@ -1,18 +1,12 @@
 package railway
 
-import (
-	"github.com/ferretcode/pricetag/sink"
-	"github.com/ferretcode/pricetag/types"
-)
-
 // REQUIRED ENVIRONMENT VARIABLES:
 // RAILWAY_API_KEY=
-// RAILWAY_ENVIRONMENT_ID=
+//
+// the streamed environments are configured on the services page.
+// RAILWAY_ENVIRONMENT_ID is added there on the first start
 
-func CreateSink() sink.Sink {
-	newLogChan := make(chan []types.Log)
-
-	return sink.Sink{
-		NewLog: newLogChan,
-	}
-}
+const (
+	BaseURL             = "https://backboard.railway.app/graphql/v2"
+	BaseSubscriptionURL = "wss://backboard.railway.app/graphql/v2"
+)
@@ -3,9 +3,11 @@ package railway
 import (
 	"context"
 	"errors"
+
+	"github.com/ferretcode/pricetag/types"
 )
 
-func (gql *GraphQLConfig) getProjectInfo(ctx context.Context, config *Config) (*project, error) {
+func (gql *GraphQLConfig) getProjectInfo(ctx context.Context, environmentID string) (*project, error) {
 	if gql.client == nil {
 		return nil, errors.New("client must not be nil")
 	}
@@ -13,7 +15,7 @@ func (gql *GraphQLConfig) getProjectInfo(ctx context.Context, config *Config) (*
 	environment := &environment{}
 
 	variables := map[string]interface{}{
-		"id": config.EnvironmentId,
+		"id": environmentID,
 	}
 
 	if err := gql.client.Exec(ctx, environmentQuery, &environment, variables); err != nil {
@@ -33,12 +35,35 @@ func (gql *GraphQLConfig) getProjectInfo(ctx context.Context, config *Config) (*
 	return project, nil
 }
 
-func (gql *GraphQLConfig) GetEnvironments(ctx context.Context, config *Config) (environments map[string]string, err error) {
-	project, err := gql.getProjectInfo(ctx, config)
+// LookupEnvironment resolves the names of an environment and its project
+func (gql *GraphQLConfig) LookupEnvironment(ctx context.Context, environmentID string) (types.RailwayEnvironment, error) {
+	project, err := gql.getProjectInfo(ctx, environmentID)
+	if err != nil {
+		return types.RailwayEnvironment{}, err
+	}
+
+	for _, environment := range project.Project.Environments.Edges {
+		if environment.Node.ID == environmentID {
+			return types.RailwayEnvironment{
+				EnvironmentID: environmentID,
+				Name:          environment.Node.Name,
+				ProjectID:     project.Project.ID,
+				ProjectName:   project.Project.Name,
+			}, nil
+		}
+	}
+
+	return types.RailwayEnvironment{}, errors.New("environment not found in its project")
+}
+
+func (gql *GraphQLConfig) GetEnvironments(ctx context.Context, environmentID string) (environments map[string]string, err error) {
+	project, err := gql.getProjectInfo(ctx, environmentID)
 	if err != nil {
 		return nil, err
 	}
 
+	environments = make(map[string]string)
+
 	for _, environment := range project.Project.Environments.Edges {
 		environments[environment.Node.ID] = environment.Node.Name
 	}
@@ -46,14 +71,27 @@ func (gql *GraphQLConfig) GetEnvironments(ctx context.Context, config *Config) (
 	return
 }
 
+// GetServices lists the services of every project with a streamed
+// environment
 func (gql *GraphQLConfig) GetServices(ctx context.Context, config *Config) (services map[string]string, err error) {
-	project, err := gql.getProjectInfo(ctx, config)
-	if err != nil {
-		return nil, err
-	}
+	services = make(map[string]string)
+	projects := map[string]bool{}
+
+	for _, environment := range config.Environments() {
+		if projects[environment.ProjectID] {
+			continue
+		}
+
+		projects[environment.ProjectID] = true
+
+		project, err := gql.getProjectInfo(ctx, environment.EnvironmentID)
+		if err != nil {
+			return nil, err
+		}
 
-	for _, service := range project.Project.Services.Edges {
-		services[service.Node.ID] = service.Node.Name
+		for _, service := range project.Project.Services.Edges {
+			services[service.Node.ID] = service.Node.Name
+		}
 	}
 
 	return
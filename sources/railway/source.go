@@ -0,0 +1,163 @@
+package railway
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ferretcode/pricetag/matcher"
+	"github.com/ferretcode/pricetag/sink"
+	"github.com/ferretcode/pricetag/sources"
+	"github.com/ferretcode/pricetag/types"
+)
+
+// Source streams the logs of the tracked services of every configured
+// Railway environment, one supervised stream per environment
+type Source struct {
+	gql     *GraphQLConfig
+	config  *Config
+	cursors *CursorStore
+	// transport carries the subscriptions of every environment
+	transport *sharedTransport
+	// tagMatcher narrows the streams when the config is TaggedOnly
+	tagMatcher *matcher.Matcher
+
+	mu      sync.Mutex
+	streams map[string]*environmentStream
+}
+
+type environmentStream struct {
+	environment types.RailwayEnvironment
+	health      *sources.Health
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+func NewSource(gql *GraphQLConfig, config *Config, cursors *CursorStore, tagMatcher *matcher.Matcher) *Source {
+	return &Source{
+		gql:        gql,
+		config:     config,
+		cursors:    cursors,
+		transport:  newSharedTransport(gql),
+		tagMatcher: tagMatcher,
+		streams:    map[string]*environmentStream{},
+	}
+}
+
+func (s *Source) Name() string {
+	return "railway"
+}
+
+// Start runs a stream per environment, starting and stopping streams as
+// environments are added and removed, until ctx is done
+func (s *Source) Start(ctx context.Context, sink *sink.Sink) error {
+	defer s.stopAll()
+
+	for {
+		s.sync(ctx, sink)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.config.Changed():
+		}
+	}
+}
+
+// sync makes the running streams match the configured environments
+func (s *Source) sync(ctx context.Context, sink *sink.Sink) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	configured := map[string]types.RailwayEnvironment{}
+	for _, environment := range s.config.Environments() {
+		configured[environment.EnvironmentID] = environment
+	}
+
+	for environmentID, stream := range s.streams {
+		if _, ok := configured[environmentID]; !ok {
+			stream.cancel()
+			<-stream.done
+			delete(s.streams, environmentID)
+		}
+	}
+
+	// nothing is left to stream, so the connection isn't kept alive
+	if len(configured) == 0 {
+		s.transport.Close()
+	}
+
+	for environmentID, environment := range configured {
+		if _, ok := s.streams[environmentID]; ok {
+			continue
+		}
+
+		streamCtx, cancel := context.WithCancel(ctx)
+
+		stream := &environmentStream{
+			environment: environment,
+			health:      sources.NewHealth("railway " + environment.ProjectName + "/" + environment.Name),
+			cancel:      cancel,
+			done:        make(chan struct{}),
+		}
+
+		s.streams[environmentID] = stream
+
+		go func() {
+			defer close(stream.done)
+			s.gql.SubscribeToLogs(streamCtx, environmentID, s.transport, s.config, s.cursors, s.tagMatcher, stream.health, sink)
+		}()
+	}
+}
+
+func (s *Source) stopAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for environmentID, stream := range s.streams {
+		stream.cancel()
+		<-stream.done
+		delete(s.streams, environmentID)
+	}
+
+	s.transport.Close()
+}
+
+// Health reports the connection of each environment stream
+func (s *Source) Health() []sources.Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]sources.Status, 0, len(s.streams))
+	for _, stream := range s.streams {
+		statuses = append(statuses, stream.health.Status())
+	}
+
+	slices.SortFunc(statuses, func(a, b sources.Status) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return statuses
+}
+
+// HealthCheck checks the api key can read every configured environment
+func (s *Source) HealthCheck(ctx context.Context) error {
+	for _, environment := range s.config.Environments() {
+		if _, err := s.gql.getProjectInfo(ctx, environment.EnvironmentID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Client returns the api client, used by the dashboard to list services
+func (s *Source) Client() *GraphQLConfig {
+	return s.gql
+}
+
+// Config returns the config shared with the running subscription
+func (s *Source) Config() *Config {
+	return s.config
+}
@@ -1,231 +1,384 @@
 package railway
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"net/http"
+	"slices"
 	"time"
 
 	"github.com/charmbracelet/log"
-	"github.com/coder/websocket"
-	"github.com/google/uuid"
+	"github.com/ferretcode/pricetag/matcher"
+	"github.com/ferretcode/pricetag/sink"
+	"github.com/ferretcode/pricetag/sources"
+	"github.com/ferretcode/pricetag/types"
 )
 
-type operationMessage struct {
-	Id      string           `json:"id"`
-	Type    string           `json:"type"`
-	Payload subscribePayload `json:"payload"`
-}
-
 type subscribePayload struct {
-	Query     string     `json:"query"`
-	Variables *variables `json:"variables"`
+	Query     string `json:"query"`
+	Variables any    `json:"variables"`
 }
 
 type variables struct {
 	EnvironmentId string `json:"environmentId"`
 	Filter        string `json:"filter"`
-	BeforeLimit   int64  `json:"beforeLimit"`
-	BeforeDate    string `json:"beforeDate"`
+	BeforeLimit   int64  `json:"beforeLimit,omitempty"`
+	BeforeDate    string `json:"beforeDate,omitempty"`
+	AnchorDate    string `json:"anchorDate,omitempty"`
+	AfterDate     string `json:"afterDate,omitempty"`
+	AfterLimit    int64  `json:"afterLimit,omitempty"`
 }
 
+const (
+	// pageSize is the afterLimit of each subscription. A first message that
+	// holds this many logs means the backfill isn't done
+	pageSize = 500
+	// initialBackfill is how far back an environment that was never
+	// streamed before starts
+	initialBackfill = 5 * time.Minute
+
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 2 * time.Minute
+	// failedAfter is how many reconnects in a row may fail before the
+	// stream is reported failed. It keeps retrying at the max delay
+	failedAfter = 5
+)
+
 var (
-	connectionInit = []byte(`{"type":"connection_init"}`)
-	connectionAck  = []byte(`{"type":"connection_ack"}`)
+	errPageFull = errors.New("the backfill page is full")
+	// errFilterChanged ends a subscription whose filter is out of date, it
+	// resubscribes from the cursor straight away
+	errFilterChanged = errors.New("the log filter changed")
 )
 
-func (gql *GraphQLConfig) buildMetadataMap(ctx context.Context, config *Config) (map[string]string, error) {
-	services, err := gql.GetServices(ctx, config)
+func (gql *GraphQLConfig) buildMetadataMap(ctx context.Context, environmentID string) (map[string]string, error) {
+	project, err := gql.getProjectInfo(ctx, environmentID)
 	if err != nil {
 		return nil, err
 	}
 
-	environments, err := gql.GetEnvironments(ctx, config)
-	if err != nil {
-		return nil, err
+	metadata := make(map[string]string)
+
+	metadata[project.Project.ID] = project.Project.Name
+
+	for _, service := range project.Project.Services.Edges {
+		metadata[service.Node.ID] = service.Node.Name
 	}
 
-	for k, v := range environments {
-		services[k] = v
+	for _, environment := range project.Project.Environments.Edges {
+		metadata[environment.Node.ID] = environment.Node.Name
 	}
 
-	return services, nil
+	return metadata, nil
 }
 
-func (gql *GraphQLConfig) createSubscription(ctx context.Context, config *Config) (*websocket.Conn, error) {
-	if config == nil {
-		return nil, errors.New("config must be present")
+// logVariables asks for up to pageSize logs from the cursor on in the first
+// message, then new logs as they are emitted. Only the after arguments are
+// set, the before ones are left out of the request
+func logVariables(environmentID string, cursor Cursor, filter string) *variables {
+	from := cursor.Timestamp.UTC().Format(time.RFC3339Nano)
+
+	return &variables{
+		EnvironmentId: environmentID,
+		Filter:        filter,
+		AnchorDate:    from,
+		AfterDate:     from,
+		AfterLimit:    pageSize,
 	}
+}
 
-	subscribePayload := &subscribePayload{
-		Query: streamEnvironmentLogsQuery,
-		Variables: &variables{
-			EnvironmentId: config.EnvironmentId,
-			BeforeDate:    time.Now().UTC().Add(-5 * time.Minute).Format(time.RFC3339Nano),
-			BeforeLimit:   500,
-		}}
-
-	operationMessage := operationMessage{
-		Id:      uuid.Must(uuid.NewUUID()).String(),
-		Type:    "subscribe",
-		Payload: *subscribePayload,
+// SubscribeToLogs streams an environment from its persisted cursor, so a
+// restart or a dropped connection neither loses nor repeats logs. Backfill
+// is paged: a full first page is followed by a new subscription from its
+// last log until the stream has caught up. Every environment subscribes on
+// the shared connection. Failures are retried with backoff until the context
+// is done, and reported to health
+func (gql *GraphQLConfig) SubscribeToLogs(ctx context.Context, environmentID string, shared *sharedTransport, config *Config, cursors *CursorStore, tagMatcher *matcher.Matcher, health *sources.Health, logSink *sink.Sink) error {
+	backoff := sources.Backoff{
+		Min: minReconnectDelay,
+		Max: maxReconnectDelay,
 	}
 
-	operationMessageBytes, err := json.Marshal(&operationMessage)
-	if err != nil {
-		return nil, err
+	stream := &logStream{
+		environmentID: environmentID,
+		shared:        shared,
+		config:        config,
+		cursors:       cursors,
+		tagMatcher:    tagMatcher,
+		health:        health,
+		backoff:       &backoff,
 	}
 
-	opts := &websocket.DialOptions{
-		HTTPHeader: http.Header{
-			"Authorization": []string{"Bearer" + gql.AuthToken},
-			"Content-Type":  []string{"application/json"},
-		},
-		Subprotocols: []string{"graphql-transport-ws"},
-	}
+	for {
+		err := gql.stream(ctx, stream, logSink)
 
-	timeout, cancel := context.WithTimeout(ctx, (10 * time.Second))
-	defer cancel()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 
-	conn, _, err := websocket.Dial(timeout, gql.BaseSubscriptionURL, opts)
-	if err != nil {
-		return nil, err
+		if errors.Is(err, errPageFull) {
+			log.Debug("fetching the next page of logs", "after", stream.cursor.Timestamp)
+			continue
+		}
+
+		if errors.Is(err, errFilterChanged) {
+			log.Debug("resubscribing with the new log filter", "environment", environmentID)
+			continue
+		}
+
+		state := sources.StateReconnecting
+		if backoff.Attempts() >= failedAfter {
+			state = sources.StateFailed
+		}
+
+		health.Failed(state, err)
+
+		delay := backoff.Next()
+
+		log.Error("resubscribing to logs endpoint", "environment", environmentID, "reason", err, "after", stream.cursor.Timestamp, "delay", delay, "state", state)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
+}
 
-	conn.SetReadLimit(-1)
+// logStream is the state a subscription carries across reconnects
+type logStream struct {
+	environmentID string
+	shared        *sharedTransport
+	config        *Config
+	cursors       *CursorStore
+	tagMatcher    *matcher.Matcher
+	health        *sources.Health
+	backoff       *sources.Backoff
+	idToNameMap   map[string]string
+	cursor        Cursor
+	loaded        bool
+}
 
-	if err := conn.Write(ctx, websocket.MessageText, connectionInit); err != nil {
-		return nil, err
+// tagged drops the logs that match no tag when the config asks for it.
+// Railway's filter lets some through, or holds no tags at all when one of
+// them can't be expressed
+func (stream *logStream) tagged(logs []types.Log) []types.Log {
+	if !stream.config.TaggedOnly {
+		return logs
 	}
 
-	_, ackMessage, err := conn.Read(ctx)
-	if err != nil {
-		return nil, err
+	return slices.DeleteFunc(logs, func(newLog types.Log) bool {
+		return len(stream.tagMatcher.Match(newLog)) == 0
+	})
+}
+
+// park waits for a service to be tracked. Without one every log would be
+// dropped, so nothing is subscribed to, which isn't a failure
+func (stream *logStream) park(ctx context.Context, servicesChanged <-chan struct{}) error {
+	log.Info("no services are tracked, waiting for one before streaming", "environment", stream.environmentID)
+
+	stream.health.Connected()
+	stream.backoff.Reset()
+
+	select {
+	case <-servicesChanged:
+		return errFilterChanged
+	case <-ctx.Done():
+		return ctx.Err()
 	}
+}
 
-	if !bytes.Equal(ackMessage, connectionAck) {
-		return nil, errors.New("did not receive connection acknowledgement from server")
+// stream runs one subscription until it fails. The metadata and the cursor
+// are loaded on the first attempt that gets that far
+func (gql *GraphQLConfig) stream(ctx context.Context, stream *logStream, logSink *sink.Sink) error {
+	// the channel is taken before the services, so a change made while the
+	// filter is built isn't missed
+	servicesChanged := stream.config.ServicesChanged()
+	serviceIDs := stream.config.TrackedServiceIds()
+
+	if len(serviceIDs) == 0 {
+		return stream.park(ctx, servicesChanged)
 	}
 
-	if err := conn.Write(ctx, websocket.MessageText, operationMessageBytes); err != nil {
-		return nil, err
+	if stream.idToNameMap == nil {
+		idToNameMap, err := gql.buildMetadataMap(ctx, stream.environmentID)
+		if err != nil {
+			return err
+		}
+
+		stream.idToNameMap = idToNameMap
 	}
 
-	return conn, nil
-}
+	if !stream.loaded {
+		cursor, ok, err := stream.cursors.Load(stream.environmentID)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			cursor = Cursor{
+				Timestamp: time.Now().UTC().Add(-initialBackfill),
+			}
+		}
+
+		stream.cursor = cursor
+		stream.loaded = true
+	}
 
-func (gql *GraphQLConfig) SubscribeToLogs(ctx context.Context, config *Config) error {
-	idToNameMap, err := gql.buildMetadataMap(ctx, config)
+	transport, err := stream.shared.get(ctx)
 	if err != nil {
 		return err
 	}
 
-	conn, err := gql.createSubscription(ctx, config)
+	// the channel is taken before the tags, as for the services
+	tagsChanged := stream.tagMatcher.Changed()
+
+	tags := []types.Tag{}
+	if stream.config.TaggedOnly {
+		tags = stream.tagMatcher.Tags()
+	} else {
+		// the tags don't narrow the filter, so changing them changes nothing
+		tagsChanged = nil
+	}
+
+	filter := buildFilter(serviceIDs, tags, stream.config.TaggedOnly)
+
+	subscription, err := transport.Subscribe(ctx, streamEnvironmentLogsQuery, logVariables(stream.environmentID, stream.cursor, filter))
 	if err != nil {
 		return err
 	}
-	defer conn.CloseNow()
+	defer subscription.Close()
 
-	LogTime := time.Now().UTC()
+	stream.health.Connected()
 
-	for {
-		_, logPayload, err := safeConnRead(conn, ctx)
-		if err != nil {
-			log.Error("resubscribing to logs endpoint", "reason", err)
+	subscriptionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-			safeConnCloseNow(conn)
+	go func() {
+		select {
+		case <-servicesChanged:
+		case <-tagsChanged:
+		case <-subscriptionCtx.Done():
+		}
 
-			conn, err = gql.createSubscription(ctx, config)
-			if err != nil {
-				return err
-			}
+		cancel()
+	}()
 
-			continue
+	stream.cursor, err = gql.readLogs(subscriptionCtx, subscription, stream, logSink)
+
+	if ctx.Err() == nil && subscriptionCtx.Err() != nil {
+		return errFilterChanged
+	}
+
+	return err
+}
+
+// readLogs delivers logs after the cursor until the subscription ends, or
+// returns errPageFull once a full first page shows more history is waiting.
+// The returned cursor is the last delivered log
+func (gql *GraphQLConfig) readLogs(ctx context.Context, subscription *subscription, stream *logStream, logSink *sink.Sink) (Cursor, error) {
+	config, idToNameMap, cursor := stream.config, stream.idToNameMap, stream.cursor
+
+	for first := true; ; first = false {
+		payload, err := subscription.Next(ctx)
+		if err != nil {
+			return cursor, err
 		}
 
-		logs := &logPayloadResponse{}
+		logs := logPayload{}
 
-		if err := json.Unmarshal(logPayload, &logs); err != nil {
-			return err
+		if err := json.Unmarshal(payload, &logs); err != nil {
+			return cursor, err
 		}
 
-		if logs.Type != TypeNext {
-			log.Error("resubscribing to logs endpoint", "reason", fmt.Sprintf("log type not next: %s", logs.Type))
+		// a next message may carry the errors of executing the query
+		if len(logs.Errors) > 0 {
+			return cursor, &SubscriptionError{Messages: errorMessages(logs.Errors)}
+		}
 
-			safeConnCloseNow(conn)
+		// a subscription that delivers is healthy, one that is acknowledged
+		// and then drops keeps backing off
+		stream.health.Message()
+		stream.backoff.Reset()
 
-			conn, err = gql.createSubscription(ctx, config)
-			if err != nil {
-				return err
-			}
+		environmentLogs := logs.Data.EnvironmentLogs
 
-			continue
-		}
+		// the cursor only moves forward, so logs are handled in time order
+		slices.SortStableFunc(environmentLogs, func(a, b railwayLog) int {
+			return a.Timestamp.Compare(b.Timestamp)
+		})
 
+		fresh, next := cursor.Advance(environmentLogs)
 		filteredLogs := []railwayLog{}
 
-		for i := range logs.Payload.Data.EnvironmentLogs {
-			LogTime = logs.Payload.Data.EnvironmentLogs[i].Timestamp
-
-			if logs.Payload.Data.EnvironmentLogs[i].Timestamp.Before(LogTime) || LogTime == logs.Payload.Data.EnvironmentLogs[i].Timestamp {
-				log.Debug("skipping stale log message")
+		for i := range fresh {
+			if !config.IsTracked(fresh[i].Tags.ServiceID) {
 				continue
 			}
 
-			serviceName, ok := idToNameMap[logs.Payload.Data.EnvironmentLogs[i].Tags.ServiceID]
+			serviceName, ok := idToNameMap[fresh[i].Tags.ServiceID]
 			if !ok {
 				log.Warn("service name not found")
 				serviceName = "undefined"
 			}
 
-			logs.Payload.Data.EnvironmentLogs[i].Tags.ServiceName = serviceName
+			fresh[i].Tags.ServiceName = serviceName
 
-			environmentName, ok := idToNameMap[logs.Payload.Data.EnvironmentLogs[i].Tags.EnvironmentID]
+			environmentName, ok := idToNameMap[fresh[i].Tags.EnvironmentID]
 			if !ok {
 				log.Warn("environment name not found")
 				environmentName = "undefined"
 			}
 
-			logs.Payload.Data.EnvironmentLogs[i].Tags.EnvironmentName = environmentName
+			fresh[i].Tags.EnvironmentName = environmentName
 
-			projectName, ok := idToNameMap[logs.Payload.Data.EnvironmentLogs[i].Tags.ProjectID]
+			projectName, ok := idToNameMap[fresh[i].Tags.ProjectID]
 			if !ok {
 				log.Warn("project name not found")
 				projectName = "undefined"
 			}
 
-			logs.Payload.Data.EnvironmentLogs[i].Tags.ProjectName = projectName
+			fresh[i].Tags.ProjectName = projectName
 
-			filteredLogs = append(filteredLogs, logs.Payload.Data.EnvironmentLogs[i])
+			filteredLogs = append(filteredLogs, fresh[i])
 		}
 
-		if len(filteredLogs) == 0 {
-			continue
+		// the cursor isn't moved past logs that weren't delivered, the
+		// stream resubscribes from it instead
+		if newLogs := stream.tagged(toLogs(filteredLogs)); len(newLogs) > 0 {
+			select {
+			case logSink.NewLog <- newLogs:
+			case <-ctx.Done():
+				return cursor, ctx.Err()
+			}
 		}
 
-		// TODO: reconstruct log message
-	}
-}
+		if len(fresh) > 0 {
+			cursor = next
 
-func safeConnRead(conn *websocket.Conn, ctx context.Context) (mT websocket.MessageType, b []byte, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = fmt.Errorf("recovered from panic: %v", r)
+			if err := stream.cursors.Save(stream.environmentID, cursor); err != nil {
+				log.Error("error saving the railway cursor", "err", err)
+			}
 		}
-	}()
 
-	return conn.Read(ctx)
-}
+		if first && len(environmentLogs) >= pageSize {
+			// a full page with nothing new holds only logs already seen at
+			// the cursor's timestamp, so the page can't reach past them.
+			// Paging goes on right after that timestamp, giving up on the
+			// logs at it that didn't fit
+			if len(fresh) == 0 {
+				log.Warn("skipping logs that share a timestamp with more than a page of others", "environment", stream.environmentID, "timestamp", cursor.Timestamp)
+
+				cursor = Cursor{
+					Timestamp: cursor.Timestamp.Add(time.Nanosecond),
+				}
+
+				if err := stream.cursors.Save(stream.environmentID, cursor); err != nil {
+					log.Error("error saving the railway cursor", "err", err)
+				}
+			}
 
-func safeConnCloseNow(conn *websocket.Conn) (err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = fmt.Errorf("recovered from panic: %v", r)
+			return cursor, errPageFull
 		}
-	}()
-
-	return conn.CloseNow()
+	}
 }
@@ -0,0 +1,463 @@
+package railway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/google/uuid"
+)
+
+// the message types of the graphql-transport-ws protocol
+const (
+	typeConnectionInit = "connection_init"
+	typeConnectionAck  = "connection_ack"
+	typePing           = "ping"
+	typePong           = "pong"
+	typeSubscribe      = "subscribe"
+	typeNext           = "next"
+	typeError          = "error"
+	typeComplete       = "complete"
+)
+
+const (
+	dialTimeout = 10 * time.Second
+	// keepaliveInterval is how often a ping is sent. A connection that
+	// hears nothing, not even a pong, for keepaliveTimeout is dead
+	keepaliveInterval = 15 * time.Second
+	keepaliveTimeout  = 45 * time.Second
+
+	// subscriptionBuffer is how many payloads a subscription may fall
+	// behind before it is ended rather than holding up the connection
+	subscriptionBuffer = 64
+)
+
+var (
+	errSubscriptionComplete = errors.New("the server completed the subscription")
+	errConnectionClosed     = errors.New("the connection was closed")
+	errSubscriptionOverflow = errors.New("the subscription fell too far behind")
+)
+
+type transportMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type graphqlError struct {
+	Message string `json:"message"`
+}
+
+// SubscriptionError is the error payload the server sent for a subscription
+type SubscriptionError struct {
+	Messages []string
+}
+
+func (e *SubscriptionError) Error() string {
+	return "subscription error: " + strings.Join(e.Messages, "; ")
+}
+
+func newSubscriptionError(payload json.RawMessage) *SubscriptionError {
+	graphqlErrors := []graphqlError{}
+
+	if err := json.Unmarshal(payload, &graphqlErrors); err != nil || len(graphqlErrors) == 0 {
+		return &SubscriptionError{Messages: []string{string(payload)}}
+	}
+
+	return &SubscriptionError{Messages: errorMessages(graphqlErrors)}
+}
+
+func errorMessages(graphqlErrors []graphqlError) []string {
+	messages := make([]string, 0, len(graphqlErrors))
+	for _, graphqlError := range graphqlErrors {
+		messages = append(messages, graphqlError.Message)
+	}
+
+	return messages
+}
+
+// transport is a graphql-transport-ws connection. It answers pings, sends
+// keepalives and runs any number of subscriptions, routed by operation id
+type transport struct {
+	conn *websocket.Conn
+
+	writeMu sync.Mutex
+
+	mu            sync.Mutex
+	subscriptions map[string]*subscription
+	err           error
+
+	// lastReceived is the unix nanoseconds of the last message of any type
+	lastReceived atomic.Int64
+
+	done   chan struct{}
+	cancel context.CancelFunc
+}
+
+// subscription is one operation on a transport
+type subscription struct {
+	id        string
+	transport *transport
+	messages  chan json.RawMessage
+
+	// err is set before done is closed
+	err  error
+	done chan struct{}
+}
+
+// dial opens a connection and waits for the server to acknowledge it. The
+// connection lives until Close or until it fails, not until ctx is done
+func (gql *GraphQLConfig) dial(ctx context.Context) (*transport, error) {
+	opts := &websocket.DialOptions{
+		HTTPHeader: http.Header{
+			"Authorization": []string{"Bearer " + gql.AuthToken},
+			"Content-Type":  []string{"application/json"},
+		},
+		Subprotocols: []string{"graphql-transport-ws"},
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(timeout, gql.BaseSubscriptionURL, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetReadLimit(-1)
+
+	runCtx, runCancel := context.WithCancel(context.Background())
+
+	t := &transport{
+		conn:          conn,
+		subscriptions: map[string]*subscription{},
+		done:          make(chan struct{}),
+		cancel:        runCancel,
+	}
+
+	t.lastReceived.Store(time.Now().UnixNano())
+
+	if err := t.handshake(timeout); err != nil {
+		runCancel()
+		conn.CloseNow()
+		return nil, err
+	}
+
+	go t.read(runCtx)
+	go t.keepalive(runCtx)
+
+	return t, nil
+}
+
+// handshake sends connection_init and waits for connection_ack, answering
+// pings in the meantime
+func (t *transport) handshake(ctx context.Context) error {
+	if err := t.write(ctx, transportMessage{Type: typeConnectionInit}); err != nil {
+		return err
+	}
+
+	for {
+		_, data, err := t.conn.Read(ctx)
+		if err != nil {
+			return fmt.Errorf("did not receive connection acknowledgement from server: %w", err)
+		}
+
+		message := transportMessage{}
+
+		if err := json.Unmarshal(data, &message); err != nil {
+			return err
+		}
+
+		switch message.Type {
+		case typeConnectionAck:
+			return nil
+		case typePing:
+			if err := t.write(ctx, transportMessage{Type: typePong, Payload: message.Payload}); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("expected connection_ack, got %s", message.Type)
+		}
+	}
+}
+
+func (t *transport) write(ctx context.Context, message transportMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	return t.conn.Write(ctx, websocket.MessageText, data)
+}
+
+// read routes every incoming message until the connection fails
+func (t *transport) read(ctx context.Context) {
+	for {
+		_, data, err := t.conn.Read(ctx)
+		if err != nil {
+			t.fail(err)
+			return
+		}
+
+		t.lastReceived.Store(time.Now().UnixNano())
+
+		message := transportMessage{}
+
+		if err := json.Unmarshal(data, &message); err != nil {
+			t.fail(fmt.Errorf("invalid message: %w", err))
+			return
+		}
+
+		switch message.Type {
+		case typePing:
+			if err := t.write(ctx, transportMessage{Type: typePong, Payload: message.Payload}); err != nil {
+				t.fail(err)
+				return
+			}
+		case typePong:
+		case typeNext:
+			// a slow subscription is ended instead of waited for, waiting
+			// would hold up pongs and every other subscription
+			if s := t.subscription(message.ID); s != nil {
+				select {
+				case s.messages <- message.Payload:
+				default:
+					if err := t.complete(ctx, message.ID, errSubscriptionOverflow); err != nil {
+						t.fail(err)
+						return
+					}
+				}
+			}
+		case typeError:
+			t.finish(message.ID, newSubscriptionError(message.Payload))
+		case typeComplete:
+			t.finish(message.ID, errSubscriptionComplete)
+		default:
+			t.fail(fmt.Errorf("unexpected message type %q", message.Type))
+			return
+		}
+	}
+}
+
+// keepalive pings the server, and closes a connection that has gone quiet
+// so a half open socket doesn't stall the subscriptions forever
+func (t *transport) keepalive(ctx context.Context) {
+	ticker := time.NewTicker(keepaliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if time.Since(time.Unix(0, t.lastReceived.Load())) > keepaliveTimeout {
+			t.fail(errors.New("keepalive timed out"))
+			return
+		}
+
+		writeCtx, cancel := context.WithTimeout(ctx, dialTimeout)
+		err := t.write(writeCtx, transportMessage{Type: typePing})
+		cancel()
+
+		if err != nil {
+			t.fail(err)
+			return
+		}
+	}
+}
+
+// Subscribe starts an operation with a new id. Its payloads are read with
+// Next until it fails, and Close must be called once it is not needed
+func (t *transport) Subscribe(ctx context.Context, query string, variables any) (*subscription, error) {
+	payload, err := json.Marshal(subscribePayload{
+		Query:     query,
+		Variables: variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &subscription{
+		id:        uuid.Must(uuid.NewUUID()).String(),
+		transport: t,
+		// a little room so a slow consumer doesn't hold up pongs at once
+		messages: make(chan json.RawMessage, subscriptionBuffer),
+		done:     make(chan struct{}),
+	}
+
+	t.mu.Lock()
+	if t.err != nil {
+		t.mu.Unlock()
+		return nil, t.err
+	}
+	t.subscriptions[s.id] = s
+	t.mu.Unlock()
+
+	err = t.write(ctx, transportMessage{
+		ID:      s.id,
+		Type:    typeSubscribe,
+		Payload: payload,
+	})
+	if err != nil {
+		t.finish(s.id, err)
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (t *transport) subscription(id string) *subscription {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.subscriptions[id]
+}
+
+// finish ends a subscription with err, returning whether it was still active
+func (t *transport) finish(id string, err error) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.subscriptions[id]
+	if !ok {
+		return false
+	}
+
+	delete(t.subscriptions, id)
+
+	s.err = err
+	close(s.done)
+
+	return true
+}
+
+// complete ends a subscription with err and tells the server it is no
+// longer wanted
+func (t *transport) complete(ctx context.Context, id string, err error) error {
+	if !t.finish(id, err) {
+		return nil
+	}
+
+	return t.write(ctx, transportMessage{ID: id, Type: typeComplete})
+}
+
+// fail closes the connection and ends every subscription with err
+func (t *transport) fail(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.err != nil {
+		return
+	}
+
+	t.err = err
+
+	for id, s := range t.subscriptions {
+		delete(t.subscriptions, id)
+
+		s.err = err
+		close(s.done)
+	}
+
+	t.cancel()
+	t.conn.CloseNow()
+	close(t.done)
+}
+
+// Close ends the connection and its subscriptions
+func (t *transport) Close() {
+	t.fail(errConnectionClosed)
+}
+
+// Done is closed once the connection has failed or was closed
+func (t *transport) Done() <-chan struct{} {
+	return t.done
+}
+
+// Err is why the connection ended, nil while it is open
+func (t *transport) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.err
+}
+
+// Next returns the next payload, or the error payload, completion or
+// connection failure that ended the subscription
+func (s *subscription) Next(ctx context.Context) (json.RawMessage, error) {
+	select {
+	case payload := <-s.messages:
+		return payload, nil
+	case <-s.done:
+		return nil, s.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close tells the server the subscription is no longer wanted
+func (s *subscription) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+
+	// the connection may already be gone, then there is nothing to tell
+	_ = s.transport.complete(ctx, s.id, errSubscriptionComplete)
+}
+
+// sharedTransport hands every environment stream the same connection, their
+// subscriptions are routed by operation id. A connection that failed is
+// replaced by the next stream to ask for one
+type sharedTransport struct {
+	gql *GraphQLConfig
+
+	mu      sync.Mutex
+	current *transport
+}
+
+func newSharedTransport(gql *GraphQLConfig) *sharedTransport {
+	return &sharedTransport{
+		gql: gql,
+	}
+}
+
+// get returns the open connection, dialling one when there is none
+func (s *sharedTransport) get(ctx context.Context) (*transport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current != nil && s.current.Err() == nil {
+		return s.current, nil
+	}
+
+	t, err := s.gql.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.current = t
+
+	return t, nil
+}
+
+// Close ends the connection, the next get dials a new one
+func (s *sharedTransport) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current != nil {
+		s.current.Close()
+		s.current = nil
+	}
+}
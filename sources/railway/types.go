@@ -3,23 +3,14 @@ package railway
 import (
 	"time"
 
-	"github.com/ferretcode/pricetag/sink"
 	"github.com/hasura/go-graphql-client"
 )
 
-type LogType string
-
-const (
-	TypeNext     LogType = "next"
-	TypeComplete LogType = "complete"
-)
-
 type GraphQLConfig struct {
 	AuthToken           string
 	BaseSubscriptionURL string
 	BaseURL             string
 	client              *graphql.Client
-	sink                *sink.Sink
 }
 
 type environment struct {
@@ -84,11 +75,10 @@ type project struct {
 	} `json:"project"`
 }
 
-type logPayloadResponse struct {
-	Payload struct {
-		Data struct {
-			EnvironmentLogs []railwayLog `json:"environmentLogs"`
-		} `json:"data"`
-	} `json:"payload"`
-	Type LogType `json:"type"`
+// logPayload is the payload of a next message of the log subscription
+type logPayload struct {
+	Data struct {
+		EnvironmentLogs []railwayLog `json:"environmentLogs"`
+	} `json:"data"`
+	Errors []graphqlError `json:"errors"`
 }
@@ -0,0 +1,43 @@
+// Package sources defines how log providers feed the sink. Each provider
+// lives in its own package under sources and is wired up in main
+package sources
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/ferretcode/pricetag/sink"
+)
+
+// Source produces logs from a provider
+type Source interface {
+	// Name identifies the source in logs and in the SOURCES setting
+	Name() string
+	// Start sends logs to the sink until the context is cancelled or the
+	// source fails. A failed source is restarted by the caller
+	Start(ctx context.Context, sink *sink.Sink) error
+	// HealthCheck reports whether the provider is reachable with the
+	// current configuration
+	HealthCheck(ctx context.Context) error
+}
+
+// Enabled returns the source names listed in the comma separated SOURCES
+// environment variable, railway when it is unset
+func Enabled() []string {
+	value := os.Getenv("SOURCES")
+	if value == "" {
+		return []string{"railway"}
+	}
+
+	names := []string{}
+
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
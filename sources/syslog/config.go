@@ -0,0 +1,28 @@
+package syslog
+
+import (
+	"errors"
+	"os"
+)
+
+type Config struct {
+	// UDPAddr and TCPAddr are listen addresses such as ":5514", empty
+	// disables the listener
+	UDPAddr string
+	TCPAddr string
+}
+
+// GenerateConfig reads SYSLOG_UDP_ADDR and SYSLOG_TCP_ADDR, at least one
+// must be set
+func GenerateConfig() (*Config, error) {
+	config := Config{
+		UDPAddr: os.Getenv("SYSLOG_UDP_ADDR"),
+		TCPAddr: os.Getenv("SYSLOG_TCP_ADDR"),
+	}
+
+	if config.UDPAddr == "" && config.TCPAddr == "" {
+		return nil, errors.New("a syslog udp or tcp address must be present")
+	}
+
+	return &config, nil
+}
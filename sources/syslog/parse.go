@@ -0,0 +1,295 @@
+package syslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ferretcode/pricetag/types"
+)
+
+const nilValue = "-"
+
+var errInvalidMessage = errors.New("invalid syslog message")
+
+// severityLevels maps syslog severities 0 (emergency) to 7 (debug) onto log
+// levels
+var severityLevels = [8]string{"error", "error", "error", "error", "warn", "info", "info", "debug"}
+
+var facilities = [24]string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// header holds the fields shared by both formats
+type header struct {
+	facility int
+	severity int
+	hostname string
+	appName  string
+	procID   string
+	msgID    string
+}
+
+// parse reads an RFC 5424 or RFC 3164 message. now is used for RFC 3164
+// timestamps, which carry no year or zone
+func parse(message []byte, now time.Time) (types.Log, error) {
+	message = bytes.TrimRight(message, "\r\n\x00")
+
+	priority, rest, err := parsePriority(message)
+	if err != nil {
+		return types.Log{}, err
+	}
+
+	h := header{
+		facility: priority / 8,
+		severity: priority % 8,
+	}
+
+	if len(rest) > 1 && rest[0] == '1' && rest[1] == ' ' {
+		return parseRFC5424(h, string(rest[2:]))
+	}
+
+	return parseRFC3164(h, string(rest), now), nil
+}
+
+func parsePriority(message []byte) (int, []byte, error) {
+	if len(message) < 3 || message[0] != '<' {
+		return 0, nil, errInvalidMessage
+	}
+
+	end := bytes.IndexByte(message[:min(len(message), 5)], '>')
+	if end < 2 {
+		return 0, nil, errInvalidMessage
+	}
+
+	priority, err := strconv.Atoi(string(message[1:end]))
+	if err != nil || priority > 191 {
+		return 0, nil, errInvalidMessage
+	}
+
+	return priority, message[end+1:], nil
+}
+
+// parseRFC5424 reads
+// TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+func parseRFC5424(h header, rest string) (types.Log, error) {
+	fields := make([]string, 5)
+
+	for i := range fields {
+		field, remaining, ok := strings.Cut(rest, " ")
+		if !ok && i < len(fields)-1 {
+			return types.Log{}, errInvalidMessage
+		}
+
+		fields[i] = field
+		rest = remaining
+	}
+
+	timestamp := time.Now().UTC()
+
+	if fields[0] != nilValue {
+		parsed, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return types.Log{}, errInvalidMessage
+		}
+
+		timestamp = parsed.UTC()
+	}
+
+	h.hostname = nilToEmpty(fields[1])
+	h.appName = nilToEmpty(fields[2])
+	h.procID = nilToEmpty(fields[3])
+	h.msgID = nilToEmpty(fields[4])
+
+	attributes, rest, err := parseStructuredData(rest)
+	if err != nil {
+		return types.Log{}, err
+	}
+
+	rest = strings.TrimPrefix(rest, " ")
+	rest = strings.TrimPrefix(rest, "\ufeff")
+
+	return toLog(h, timestamp, rest, attributes), nil
+}
+
+// parseStructuredData reads the SD-ELEMENTs of an RFC 5424 message. Each
+// param becomes the attribute "<SD-ID>.<PARAM-NAME>"
+func parseStructuredData(rest string) (map[string]string, string, error) {
+	attributes := map[string]string{}
+
+	if strings.HasPrefix(rest, nilValue) {
+		return attributes, rest[1:], nil
+	}
+
+	for strings.HasPrefix(rest, "[") {
+		end := strings.IndexAny(rest, " ]")
+		if end < 0 {
+			return nil, "", errInvalidMessage
+		}
+
+		id := rest[1:end]
+		rest = rest[end:]
+
+		for strings.HasPrefix(rest, " ") {
+			rest = rest[1:]
+
+			name, remaining, ok := strings.Cut(rest, "=\"")
+			if !ok {
+				return nil, "", errInvalidMessage
+			}
+
+			value, remaining, err := parseParamValue(remaining)
+			if err != nil {
+				return nil, "", err
+			}
+
+			attributes[id+"."+name] = value
+			rest = remaining
+		}
+
+		if !strings.HasPrefix(rest, "]") {
+			return nil, "", errInvalidMessage
+		}
+
+		rest = rest[1:]
+	}
+
+	return attributes, rest, nil
+}
+
+// parseParamValue reads a quoted PARAM-VALUE up to its closing quote,
+// unescaping \", \\ and \]
+func parseParamValue(rest string) (string, string, error) {
+	value := strings.Builder{}
+
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case '\\':
+			if i+1 < len(rest) && (rest[i+1] == '"' || rest[i+1] == '\\' || rest[i+1] == ']') {
+				i++
+			}
+			value.WriteByte(rest[i])
+		case '"':
+			return value.String(), rest[i+1:], nil
+		default:
+			value.WriteByte(rest[i])
+		}
+	}
+
+	return "", "", errInvalidMessage
+}
+
+// parseRFC3164 reads TIMESTAMP HOSTNAME TAG: MSG. BSD syslog is loosely
+// followed in practice, so when the header doesn't parse the whole line is
+// kept as the message
+func parseRFC3164(h header, rest string, now time.Time) types.Log {
+	timestamp := now.UTC()
+
+	// "Jan  2 15:04:05" is always 15 bytes
+	if len(rest) >= 16 && rest[15] == ' ' {
+		parsed, err := time.ParseInLocation(time.Stamp, rest[:15], now.Location())
+		if err == nil {
+			year := now.Year()
+			// a december message read in january belongs to last year
+			if parsed.Month() == time.December && now.Month() == time.January {
+				year--
+			}
+
+			timestamp = parsed.AddDate(year, 0, 0).UTC()
+			rest = rest[16:]
+
+			if hostname, remaining, ok := strings.Cut(rest, " "); ok && !strings.HasSuffix(hostname, ":") {
+				h.hostname = hostname
+				rest = remaining
+			}
+		}
+	}
+
+	if tag, message, ok := strings.Cut(rest, ": "); ok && isTag(tag) {
+		h.appName = tag
+
+		if open := strings.IndexByte(tag, '['); open > 0 && strings.HasSuffix(tag, "]") {
+			h.appName = tag[:open]
+			h.procID = tag[open+1 : len(tag)-1]
+		}
+
+		rest = message
+	}
+
+	return toLog(h, timestamp, rest, map[string]string{})
+}
+
+// isTag reports whether s looks like an RFC 3164 TAG, optionally followed
+// by [pid]
+func isTag(s string) bool {
+	if s == "" || len(s) > 48 {
+		return false
+	}
+
+	return !strings.ContainsAny(s, " \t")
+}
+
+func toLog(h header, timestamp time.Time, message string, attributes map[string]string) types.Log {
+	attributes["facility"] = facilityName(h.facility)
+	attributes["severity"] = strconv.Itoa(h.severity)
+
+	for key, value := range map[string]string{
+		"hostname": h.hostname,
+		"appName":  h.appName,
+		"procId":   h.procID,
+		"msgId":    h.msgID,
+	} {
+		if value != "" {
+			attributes[key] = value
+		}
+	}
+
+	serviceName := h.appName
+	if serviceName == "" {
+		serviceName = "syslog"
+	}
+
+	log := types.Log{
+		Message:    message,
+		Level:      severityLevels[h.severity],
+		Timestamp:  timestamp,
+		Attributes: attributes,
+
+		ServiceID:   "syslog:" + serviceName,
+		ServiceName: serviceName,
+	}
+
+	raw := make(map[string]string, len(attributes)+3)
+	for key, value := range attributes {
+		raw[key] = value
+	}
+
+	raw["message"] = log.Message
+	raw["level"] = log.Level
+	raw["timestamp"] = log.Timestamp.Format(time.RFC3339Nano)
+
+	log.Raw, _ = json.Marshal(raw)
+
+	return log
+}
+
+func facilityName(facility int) string {
+	if facility < len(facilities) {
+		return facilities[facility]
+	}
+
+	return strconv.Itoa(facility)
+}
+
+func nilToEmpty(value string) string {
+	if value == nilValue {
+		return ""
+	}
+
+	return value
+}
@@ -0,0 +1,150 @@
+package syslog
+
+import (
+	"maps"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		message    string
+		wantErr    bool
+		level      string
+		service    string
+		text       string
+		timestamp  time.Time
+		attributes map[string]string
+	}{
+		{
+			name:      "rfc 5424 with structured data",
+			message:   `<165>1 2024-03-10T11:59:58.123Z host01 api 4242 ID47 [exampleSDID@32473 iut="3" eventSource="App\"lication"] request done`,
+			level:     "info",
+			service:   "api",
+			text:      "request done",
+			timestamp: time.Date(2024, time.March, 10, 11, 59, 58, 123000000, time.UTC),
+			attributes: map[string]string{
+				"facility":                      "local4",
+				"severity":                      "5",
+				"hostname":                      "host01",
+				"appName":                       "api",
+				"procId":                        "4242",
+				"msgId":                         "ID47",
+				"exampleSDID@32473.iut":         "3",
+				"exampleSDID@32473.eventSource": `App"lication`,
+			},
+		},
+		{
+			name:      "rfc 5424 with nil values and a bom",
+			message:   "<11>1 2024-03-10T11:00:00+01:00 - - - - - \ufeffdisk failed\n",
+			level:     "error",
+			service:   "syslog",
+			text:      "disk failed",
+			timestamp: time.Date(2024, time.March, 10, 10, 0, 0, 0, time.UTC),
+			attributes: map[string]string{
+				"facility": "user",
+				"severity": "3",
+			},
+		},
+		{
+			name:    "rfc 5424 with an unterminated param",
+			message: `<165>1 2024-03-10T11:59:58Z host api - - [id key="value] message`,
+			wantErr: true,
+		},
+		{
+			name:    "rfc 5424 with an invalid timestamp",
+			message: `<165>1 yesterday host api - - - message`,
+			wantErr: true,
+		},
+		{
+			name:      "rfc 3164 with a pid",
+			message:   "<38>Mar  9 23:15:01 web1 sshd[812]: Accepted publickey for deploy",
+			level:     "info",
+			service:   "sshd",
+			text:      "Accepted publickey for deploy",
+			timestamp: time.Date(2024, time.March, 9, 23, 15, 1, 0, time.UTC),
+			attributes: map[string]string{
+				"facility": "auth",
+				"severity": "6",
+				"hostname": "web1",
+				"appName":  "sshd",
+				"procId":   "812",
+			},
+		},
+		{
+			name:      "rfc 3164 without a header",
+			message:   "<15>just some text",
+			level:     "debug",
+			service:   "syslog",
+			text:      "just some text",
+			timestamp: now,
+			attributes: map[string]string{
+				"facility": "user",
+				"severity": "7",
+			},
+		},
+		{
+			name:    "missing priority",
+			message: "Mar  9 23:15:01 web1 sshd: hello",
+			wantErr: true,
+		},
+		{
+			name:    "priority out of range",
+			message: "<192>1 - - - - - - hello",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsed, err := parse([]byte(test.message), now)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", parsed)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if parsed.Level != test.level {
+				t.Errorf("level = %q, want %q", parsed.Level, test.level)
+			}
+
+			if parsed.ServiceName != test.service || parsed.ServiceID != "syslog:"+test.service {
+				t.Errorf("service = %q (%q), want %q", parsed.ServiceName, parsed.ServiceID, test.service)
+			}
+
+			if parsed.Message != test.text {
+				t.Errorf("message = %q, want %q", parsed.Message, test.text)
+			}
+
+			if !parsed.Timestamp.Equal(test.timestamp) {
+				t.Errorf("timestamp = %v, want %v", parsed.Timestamp, test.timestamp)
+			}
+
+			if !maps.Equal(parsed.Attributes, test.attributes) {
+				t.Errorf("attributes = %v, want %v", parsed.Attributes, test.attributes)
+			}
+		})
+	}
+}
+
+func TestParseRFC3164YearRollover(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 0, 0, 5, 0, time.UTC)
+
+	parsed, err := parse([]byte("<13>Dec 31 23:59:59 host app: late"), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := time.Date(2024, time.December, 31, 23, 59, 59, 0, time.UTC)
+	if !parsed.Timestamp.Equal(want) {
+		t.Errorf("timestamp = %v, want %v", parsed.Timestamp, want)
+	}
+}
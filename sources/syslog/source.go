@@ -0,0 +1,314 @@
+// Package syslog receives RFC 5424 and RFC 3164 messages over udp and tcp
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/sink"
+	"github.com/ferretcode/pricetag/types"
+)
+
+const (
+	// maxMessageSize bounds a single message, the largest udp datagram
+	maxMessageSize = 64 * 1024
+	// maxOctetDigits bounds the octet count prefix of a frame, enough for
+	// any count up to maxMessageSize
+	maxOctetDigits = 6
+	// flushInterval and maxBatch bound how long and how many messages are
+	// collected before a batch is sent to the sink
+	flushInterval = 250 * time.Millisecond
+	maxBatch      = 500
+)
+
+type Source struct {
+	config *Config
+
+	mu sync.Mutex
+	// err is the last reason the listeners stopped, nil while they run
+	err error
+}
+
+func NewSource(config *Config) *Source {
+	return &Source{
+		config: config,
+	}
+}
+
+func (s *Source) Name() string {
+	return "syslog"
+}
+
+// Start listens until the context is cancelled or a listener fails
+func (s *Source) Start(ctx context.Context, logSink *sink.Sink) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	logs := make(chan types.Log, maxBatch)
+	errs := make(chan error, 2)
+	wg := &sync.WaitGroup{}
+
+	if s.config.UDPAddr != "" {
+		conn, err := net.ListenPacket("udp", s.config.UDPAddr)
+		if err != nil {
+			s.setErr(err)
+			return err
+		}
+
+		log.Info("listening for syslog", "protocol", "udp", "addr", conn.LocalAddr())
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- serveUDP(ctx, conn, logs)
+			cancel()
+		}()
+	}
+
+	if s.config.TCPAddr != "" {
+		listener, err := net.Listen("tcp", s.config.TCPAddr)
+		if err != nil {
+			cancel()
+			wg.Wait()
+			s.setErr(err)
+			return err
+		}
+
+		log.Info("listening for syslog", "protocol", "tcp", "addr", listener.Addr())
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- serveTCP(ctx, listener, logs)
+			cancel()
+		}()
+	}
+
+	s.setErr(nil)
+
+	batchErr := batch(ctx, logs, logSink)
+
+	cancel()
+	wg.Wait()
+	close(errs)
+
+	// a listener that failed is the reason the batcher stopped
+	for err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			s.setErr(err)
+			return err
+		}
+	}
+
+	return batchErr
+}
+
+// HealthCheck returns the error that last stopped the listeners
+func (s *Source) HealthCheck(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+func (s *Source) setErr(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+}
+
+// batch groups parsed messages into sink batches
+func batch(ctx context.Context, logs <-chan types.Log, logSink *sink.Sink) error {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	pending := []types.Log{}
+
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+
+		select {
+		case logSink.NewLog <- pending:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		pending = []types.Log{}
+
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case newLog := <-logs:
+			pending = append(pending, newLog)
+
+			if len(pending) >= maxBatch {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		case <-ticker.C:
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func serveUDP(ctx context.Context, conn net.PacketConn, logs chan<- types.Log) error {
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	buffer := make([]byte, maxMessageSize)
+
+	for {
+		n, _, err := conn.ReadFrom(buffer)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		if err := handleMessage(ctx, buffer[:n], logs); err != nil {
+			return err
+		}
+	}
+}
+
+func serveTCP(ctx context.Context, listener net.Listener, logs chan<- types.Log) error {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serveConn(ctx, conn, logs)
+		}()
+	}
+}
+
+// serveConn reads messages framed by octet counting (RFC 6587 3.4.1), or
+// terminated by a newline when the frame doesn't start with a length
+func serveConn(ctx context.Context, conn net.Conn, logs chan<- types.Log) {
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	reader := bufio.NewReaderSize(conn, maxMessageSize)
+
+	for {
+		message, err := readFrame(reader)
+		if err != nil {
+			if err != io.EOF && ctx.Err() == nil {
+				log.Warn("closing syslog connection", "remote", conn.RemoteAddr(), "err", err)
+			}
+			return
+		}
+
+		if err := handleMessage(ctx, message, logs); err != nil {
+			return
+		}
+	}
+}
+
+func readFrame(reader *bufio.Reader) ([]byte, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if first[0] < '0' || first[0] > '9' {
+		line, err := reader.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			return nil, errors.New("message is too long")
+		}
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return nil, err
+		}
+
+		return append([]byte(nil), line...), nil
+	}
+
+	length := []byte{}
+	for {
+		next, err := reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		if next == ' ' {
+			break
+		}
+
+		if next < '0' || next > '9' || len(length) == maxOctetDigits {
+			return nil, errors.New("invalid octet count")
+		}
+
+		length = append(length, next)
+	}
+
+	size, err := strconv.Atoi(string(length))
+	if err != nil || size <= 0 || size > maxMessageSize {
+		return nil, errors.New("invalid octet count")
+	}
+
+	message := make([]byte, size)
+
+	_, err = io.ReadFull(reader, message)
+	if err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// handleMessage parses one message, a malformed message is logged and
+// dropped
+func handleMessage(ctx context.Context, message []byte, logs chan<- types.Log) error {
+	if len(message) == 0 {
+		return nil
+	}
+
+	parsed, err := parse(message, time.Now())
+	if err != nil {
+		log.Debug("dropping malformed syslog message", "err", err)
+		return nil
+	}
+
+	select {
+	case logs <- parsed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
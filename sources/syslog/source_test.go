@@ -0,0 +1,110 @@
+package syslog
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadFrame(t *testing.T) {
+	tests := []struct {
+		name     string
+		stream   string
+		frames   []string
+		finalErr bool
+	}{
+		{
+			name:   "octet counted",
+			stream: "11 <13>1 - - -5 <13>a",
+			frames: []string{"<13>1 - - -", "<13>a"},
+		},
+		{
+			name:   "octet counted frame holding a newline",
+			stream: "9 <13>a\nb c",
+			frames: []string{"<13>a\nb c"},
+		},
+		{
+			name:   "newline terminated",
+			stream: "<13>first\n<13>second\n",
+			frames: []string{"<13>first\n", "<13>second\n"},
+		},
+		{
+			name:   "newline terminated without a final newline",
+			stream: "<13>first\n<13>last",
+			frames: []string{"<13>first\n", "<13>last"},
+		},
+		{
+			name:   "mixed framing",
+			stream: "5 <13>a<13>b\n",
+			frames: []string{"<13>a", "<13>b\n"},
+		},
+		{
+			name:     "zero length",
+			stream:   "0 <13>a",
+			finalErr: true,
+		},
+		{
+			name:     "length over the max message size",
+			stream:   "70000 <13>a",
+			finalErr: true,
+		},
+		{
+			name:     "octet count without a space",
+			stream:   strings.Repeat("1", maxMessageSize),
+			finalErr: true,
+		},
+		{
+			name:     "octet count with too many digits",
+			stream:   "0000011 <13>1 - - -",
+			finalErr: true,
+		},
+		{
+			name:     "octet count followed by something else",
+			stream:   "11<13>1 - - -",
+			finalErr: true,
+		},
+		{
+			name:     "truncated frame",
+			stream:   "20 <13>short",
+			finalErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := bufio.NewReaderSize(strings.NewReader(test.stream), maxMessageSize)
+
+			for i, want := range test.frames {
+				frame, err := readFrame(reader)
+				if err != nil {
+					t.Fatalf("frame %d: unexpected error: %v", i, err)
+				}
+
+				if string(frame) != want {
+					t.Fatalf("frame %d = %q, want %q", i, frame, want)
+				}
+			}
+
+			_, err := readFrame(reader)
+			if test.finalErr {
+				if err == nil || err == io.EOF {
+					t.Fatalf("expected a framing error, got %v", err)
+				}
+				return
+			}
+
+			if err != io.EOF {
+				t.Fatalf("expected io.EOF after the last frame, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReadFrameTooLong(t *testing.T) {
+	reader := bufio.NewReaderSize(strings.NewReader(strings.Repeat("a", maxMessageSize+1)), maxMessageSize)
+
+	if _, err := readFrame(reader); err == nil {
+		t.Fatal("expected an error for a line over the max message size")
+	}
+}
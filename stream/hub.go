@@ -0,0 +1,97 @@
+package stream
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/charmbracelet/log"
+	"github.com/ferretcode/pricetag/types"
+)
+
+const clientBufferSize = 64
+
+// Filter limits the logs a client receives. Empty fields are not filtered on
+type Filter struct {
+	Tag       string
+	ServiceID string
+	Level     string
+}
+
+func (f Filter) Matches(newLog types.Log) bool {
+	if f.Tag != "" && !slices.Contains(newLog.Tags, f.Tag) {
+		return false
+	}
+
+	if f.ServiceID != "" && f.ServiceID != newLog.ServiceID {
+		return false
+	}
+
+	if f.Level != "" && f.Level != newLog.Level {
+		return false
+	}
+
+	return true
+}
+
+type Client struct {
+	Logs   chan []types.Log
+	filter Filter
+}
+
+// Hub fans logs from the sink out to every connected live viewer
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[*Client]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		clients: make(map[*Client]struct{}),
+	}
+}
+
+func (h *Hub) Subscribe(filter Filter) *Client {
+	client := &Client{
+		Logs:   make(chan []types.Log, clientBufferSize),
+		filter: filter,
+	}
+
+	h.mu.Lock()
+	h.clients[client] = struct{}{}
+	h.mu.Unlock()
+
+	return client
+}
+
+func (h *Hub) Unsubscribe(client *Client) {
+	h.mu.Lock()
+	delete(h.clients, client)
+	h.mu.Unlock()
+}
+
+// Handle is a sink.Handler. Slow clients miss logs instead of holding up the
+// sink
+func (h *Hub) Handle(logs []types.Log) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients {
+		filtered := []types.Log{}
+
+		for i := range logs {
+			if client.filter.Matches(logs[i]) {
+				filtered = append(filtered, logs[i])
+			}
+		}
+
+		if len(filtered) == 0 {
+			continue
+		}
+
+		select {
+		case client.Logs <- filtered:
+		default:
+			log.Warn("live viewer is falling behind, dropping logs", "count", len(filtered))
+		}
+	}
+}
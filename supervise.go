@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+const restartDelay = 5 * time.Second
+
+// supervise runs fn in a goroutine and restarts it whenever it returns
+// until the context is cancelled
+func supervise(ctx context.Context, wg *sync.WaitGroup, name string, fn func(ctx context.Context) error) {
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for {
+			err := fn(ctx)
+			if ctx.Err() != nil {
+				log.Info("stopped", "worker", name)
+				return
+			}
+
+			log.Error("worker exited, restarting", "worker", name, "err", err, "delay", restartDelay)
+
+			select {
+			case <-time.After(restartDelay):
+			case <-ctx.Done():
+				log.Info("stopped", "worker", name)
+				return
+			}
+		}
+	}()
+}
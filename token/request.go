@@ -0,0 +1,16 @@
+package token
+
+import (
+	"net/http"
+	"strings"
+)
+
+// IngestKey returns the ingest key of a request, sent as
+// "Authorization: Bearer <key>" or "X-Ingest-Key: <key>"
+func IngestKey(r *http.Request) string {
+	if scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(value)
+	}
+
+	return r.Header.Get("X-Ingest-Key")
+}
@@ -0,0 +1,29 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// tokenBytes is the amount of randomness in a token, 256 bits
+const tokenBytes = 32
+
+// Generate returns a random hex encoded token
+func Generate() (string, error) {
+	b := make([]byte, tokenBytes)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Hash returns the value stored in the db for a token. Tokens have enough
+// entropy that a fast hash is sufficient
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
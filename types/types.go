@@ -1,38 +1,226 @@
 package types
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type User struct {
-	ID           int    `db:"ID"`
-	Username     string `db:"Username"`
-	Password     string `db:"Password"`
-	PermissionID int    `db:"PermissionID"`
+	ID           int    `db:"ID" json:"id"`
+	Username     string `db:"Username" json:"username"`
+	Password     string `db:"Password" json:"-"`
+	PermissionID int    `db:"PermissionID" json:"permissionId"`
 }
 
 type Permission struct {
-	ID     int `db:"ID"`
-	UserID int `db:"UserID"`
+	ID     int `db:"ID" json:"id"`
+	UserID int `db:"UserID" json:"userId"`
+
+	Admin            bool `db:"Admin" json:"admin"`
+	ManageServices   bool `db:"ManageServices" json:"manageServices"`
+	ManageTags       bool `db:"ManageTags" json:"manageTags"`
+	ManageForwarding bool `db:"ManageForwarding" json:"manageForwarding"`
+	ViewLogs         bool `db:"ViewLogs" json:"viewLogs"`
+}
+
+func (p Permission) CanManageServices() bool {
+	return p.Admin || p.ManageServices
+}
+
+func (p Permission) CanManageTags() bool {
+	return p.Admin || p.ManageTags
+}
+
+func (p Permission) CanManageForwarding() bool {
+	return p.Admin || p.ManageForwarding
+}
+
+func (p Permission) CanViewLogs() bool {
+	return p.Admin || p.ViewLogs
+}
+
+func (p Permission) IsAdmin() bool {
+	return p.Admin
+}
+
+// Invite is a single use registration link. Times are unix seconds and
+// UsedAt is 0 until the invite is redeemed
+type Invite struct {
+	ID        int    `db:"ID"`
+	TokenHash string `db:"TokenHash"`
 
 	Admin            bool `db:"Admin"`
 	ManageServices   bool `db:"ManageServices"`
 	ManageTags       bool `db:"ManageTags"`
 	ManageForwarding bool `db:"ManageForwarding"`
 	ViewLogs         bool `db:"ViewLogs"`
+
+	CreatedBy int   `db:"CreatedBy"`
+	CreatedAt int64 `db:"CreatedAt"`
+	ExpiresAt int64 `db:"ExpiresAt"`
+	UsedBy    int   `db:"UsedBy"`
+	UsedAt    int64 `db:"UsedAt"`
+}
+
+// Permission returns the permission granted to the user who redeems the invite
+func (i Invite) Permission() Permission {
+	return Permission{
+		Admin:            i.Admin,
+		ManageServices:   i.ManageServices,
+		ManageTags:       i.ManageTags,
+		ManageForwarding: i.ManageForwarding,
+		ViewLogs:         i.ViewLogs,
+	}
+}
+
+func (i Invite) Used() bool {
+	return i.UsedAt != 0
+}
+
+func (i Invite) Expired() bool {
+	return time.Now().Unix() >= i.ExpiresAt
+}
+
+func (i Invite) CreatedAtTime() time.Time {
+	return time.Unix(i.CreatedAt, 0)
+}
+
+func (i Invite) ExpiresAtTime() time.Time {
+	return time.Unix(i.ExpiresAt, 0)
+}
+
+// APIToken is a personal token for the json api. It grants the flags set on
+// it, limited to what its user is currently allowed. Times are unix seconds,
+// ExpiresAt and LastUsedAt are 0 for never
+type APIToken struct {
+	ID        int    `db:"ID"`
+	UserID    int    `db:"UserID"`
+	Name      string `db:"Name"`
+	TokenHash string `db:"TokenHash"`
+
+	Admin            bool `db:"Admin"`
+	ManageServices   bool `db:"ManageServices"`
+	ManageTags       bool `db:"ManageTags"`
+	ManageForwarding bool `db:"ManageForwarding"`
+	ViewLogs         bool `db:"ViewLogs"`
+
+	CreatedAt  int64 `db:"CreatedAt"`
+	ExpiresAt  int64 `db:"ExpiresAt"`
+	LastUsedAt int64 `db:"LastUsedAt"`
+}
+
+// Scope narrows the permission of the token's user to the flags set on the
+// token
+func (t APIToken) Scope(permission Permission) Permission {
+	return Permission{
+		ID:               permission.ID,
+		UserID:           permission.UserID,
+		Admin:            t.Admin && permission.IsAdmin(),
+		ManageServices:   t.ManageServices && permission.CanManageServices(),
+		ManageTags:       t.ManageTags && permission.CanManageTags(),
+		ManageForwarding: t.ManageForwarding && permission.CanManageForwarding(),
+		ViewLogs:         t.ViewLogs && permission.CanViewLogs(),
+	}
+}
+
+func (t APIToken) Expired() bool {
+	return t.ExpiresAt != 0 && time.Now().Unix() >= t.ExpiresAt
+}
+
+func (t APIToken) CreatedAtTime() time.Time {
+	return time.Unix(t.CreatedAt, 0)
+}
+
+func (t APIToken) ExpiresAtTime() time.Time {
+	return time.Unix(t.ExpiresAt, 0)
+}
+
+func (t APIToken) LastUsedAtTime() time.Time {
+	return time.Unix(t.LastUsedAt, 0)
+}
+
+// IngestSource is an app pushing logs to /ingest. The key fields name the
+// json fields holding the message, level and timestamp, comma separated and
+// empty for the defaults
+type IngestSource struct {
+	ID           int    `db:"ID"`
+	Name         string `db:"Name"`
+	KeyHash      string `db:"KeyHash"`
+	MessageKey   string `db:"MessageKey"`
+	LevelKey     string `db:"LevelKey"`
+	TimestampKey string `db:"TimestampKey"`
+	CreatedAt    int64  `db:"CreatedAt"`
+}
+
+func (s IngestSource) CreatedAtTime() time.Time {
+	return time.Unix(s.CreatedAt, 0)
 }
 
 type Service struct {
+	ID        int    `db:"ID" json:"id"`
+	ServiceID string `db:"ServiceID" json:"serviceId"`
+	Name      string `db:"Name" json:"name"`
+}
+
+// RailwayEnvironment is an environment the railway source streams
+type RailwayEnvironment struct {
+	ID            int    `db:"ID" json:"id"`
+	EnvironmentID string `db:"EnvironmentID" json:"environmentId"`
+	Name          string `db:"Name" json:"name"`
+	ProjectID     string `db:"ProjectID" json:"projectId"`
+	ProjectName   string `db:"ProjectName" json:"projectName"`
+	CreatedAt     int64  `db:"CreatedAt" json:"createdAt"`
 }
 
 type Tag struct {
+	ID             int    `db:"ID" json:"id"`
+	Name           string `db:"Name" json:"name"`
+	Keyword        string `db:"Keyword" json:"keyword"`
+	AttributeKey   string `db:"AttributeKey" json:"attributeKey"`
+	AttributeValue string `db:"AttributeValue" json:"attributeValue"`
+	ServiceID      string `db:"ServiceID" json:"serviceId"`
+}
+
+type Pipeline struct {
+	ID   int    `db:"ID" json:"id"`
+	Name string `db:"Name" json:"name"`
+	// Kind is the destination the pipeline sends to, such as webhook or loki
+	Kind string `db:"Kind" json:"kind"`
+	URL  string `db:"URL" json:"url"`
+	// Template is the json body of each webhook request, other kinds ignore it
+	Template string `db:"Template" json:"template"`
+	// IndexPattern names the index of each elasticsearch document
+	IndexPattern string `db:"IndexPattern" json:"indexPattern"`
+	// RateLimit caps the messages per minute of a chat pipeline
+	RateLimit int `db:"RateLimit" json:"rateLimit"`
+	// TagID limits the pipeline to logs matching the tag, 0 forwards every log
+	TagID   int  `db:"TagID" json:"tagId"`
+	Enabled bool `db:"Enabled" json:"enabled"`
 }
 
 type Log struct {
-	Message   string    `json:"message"`
-	Level     string    `json:"level"`
-	Timestamp time.Time `json:"timestamp"`
+	// ID is only set on logs read back from the log store
+	ID         int64             `json:"id"`
+	Message    string            `json:"message"`
+	Level      string            `json:"level"`
+	Timestamp  time.Time         `json:"timestamp"`
+	Attributes map[string]string `json:"attributes"`
+	Tags       []string          `json:"tags"`
+
+	ServiceID            string `json:"serviceId"`
+	ServiceName          string `json:"serviceName"`
+	EnvironmentID        string `json:"environmentId"`
+	EnvironmentName      string `json:"environmentName"`
+	ProjectID            string `json:"projectId"`
+	ProjectName          string `json:"projectName"`
+	DeploymentID         string `json:"deploymentId"`
+	DeploymentInstanceID string `json:"deploymentInstanceId"`
+
+	// Raw is the reconstructed json log line as emitted by the service
+	Raw json.RawMessage `json:"raw"`
 }
 
 type Error struct {
-	Status int
-	Error  string
+	Status int    `json:"status"`
+	Error  string `json:"error"`
 }